@@ -15,6 +15,16 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/controller"
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
 	server "github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/server/http"
 	"github.com/sirupsen/logrus"
 )
@@ -30,5 +40,75 @@ func init() {
 }
 
 func main() {
-	server.StartServer("8090", logger)
+	var cluster scales.ClusterConfig
+	flag.StringVar(&cluster.Kubeconfig, "kubeconfig", "", "path to a kubeconfig file, defaults to in-cluster config, then KUBECONFIG or ~/.kube/config")
+	flag.StringVar(&cluster.Context, "context", os.Getenv("KUBE_CONTEXT"), "kubeconfig context to use")
+
+	policy := scales.DefaultPolicy()
+	flag.DurationVar(&policy.Timeout, "timeout", envDuration("API_TIMEOUT", policy.Timeout), "deadline of a single kubernetes API call, overridden per request by the timeout header")
+	flag.IntVar(&policy.Retries, "retries", envInt("API_RETRIES", policy.Retries), "retries on conflicts and transient API errors, -1 disables them, overridden per request by the retries header")
+	flag.DurationVar(&policy.Backoff, "retry-backoff", envDuration("API_RETRY_BACKOFF", policy.Backoff), "wait before the first retry")
+
+	mode := flag.String("mode", envString("MODE", "server"), "server to scale through HTTP requests, controller to reconcile ScaleTest objects")
+	workers := flag.Int("workers", envInt("WORKERS", 2), "ScaleTests reconciled at the same time in controller mode")
+	flag.Parse()
+
+	facade, err := scales.NewScalesFacade(logger, cluster, policy)
+	if err != nil {
+		logger.Fatalf("Unable to start: %s\n", err)
+	}
+
+	switch *mode {
+	case "server":
+		if err = server.StartServer("8090", facade, logger); err != nil {
+			logger.Fatalf("Server stopped: %s\n", err)
+		}
+	case "controller":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		c, err := controller.NewController(facade, logger)
+		if err != nil {
+			logger.Fatalf("Unable to start: %s\n", err)
+		}
+
+		if err = c.Run(ctx, *workers); err != nil {
+			logger.Fatalf("Controller stopped: %s\n", err)
+		}
+	default:
+		logger.Fatalf("Invalid mode %q, expected server or controller\n", *mode)
+	}
+}
+
+func envString(name, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Fatalf("Invalid %s: %s\n", name, err)
+	}
+	return duration
+}
+
+func envInt(name string, fallback int) int {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Fatalf("Invalid %s: %s\n", name, err)
+	}
+	return number
 }
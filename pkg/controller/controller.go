@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Full resync of every ScaleTest, a safety net for missed requeues
+const resyncPeriod = 10 * time.Minute
+
+// Reconciles ScaleTest objects through the facade
+type Controller struct {
+	facade   *scales.ScalesFacade
+	client   dynamic.Interface
+	informer cache.SharedIndexInformer
+	queue    workqueue.RateLimitingInterface
+	logger   *logrus.Logger
+}
+
+func NewController(facade *scales.ScalesFacade, logger *logrus.Logger) (*Controller, error) {
+	client, err := facade.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriod)
+	c := &Controller{
+		facade:   facade,
+		client:   client,
+		informer: factory.ForResource(scales.ScaleTestResource).Informer(),
+		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "scaletests"),
+		logger:   logger,
+	}
+
+	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	return c, nil
+}
+
+// Runs the workers until ctx is done
+func (c *Controller) Run(ctx context.Context, workers int) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	go c.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
+		return fmt.Errorf("unable to sync the ScaleTest cache")
+	}
+
+	c.logger.Infof("Watching ScaleTests with %d workers.\n", workers)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	requeue, err := c.sync(ctx, key.(string))
+	if err != nil {
+		c.logger.Errorf("Unable to reconcile ScaleTest %s: %s\n", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	if requeue > 0 {
+		c.queue.AddAfter(key, requeue)
+	}
+	return true
+}
+
+// Reconciles one ScaleTest, returning when to look at it again
+func (c *Controller) sync(ctx context.Context, key string) (time.Duration, error) {
+	item, exists, err := c.informer.GetIndexer().GetByKey(key)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	obj := item.(*unstructured.Unstructured).DeepCopy()
+	test, err := scales.ScaleTestFromUnstructured(obj)
+	if err != nil {
+		return 0, err
+	}
+
+	if test.DeletionTimestamp == nil && !hasFinalizer(obj) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), scales.ScaleTestFinalizer))
+		_, err = c.client.Resource(scales.ScaleTestResource).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+		return 0, err
+	}
+
+	status := obj.Object["status"]
+	requeue := c.facade.ReconcileScaleTest(ctx, test)
+	if err = test.SetStatusOn(obj); err != nil {
+		return 0, err
+	}
+
+	if !equality.Semantic.DeepEqual(status, obj.Object["status"]) {
+		obj, err = c.client.Resource(scales.ScaleTestResource).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if test.DeletionTimestamp != nil && test.Finished() && hasFinalizer(obj) {
+		return 0, c.removeFinalizer(ctx, obj)
+	}
+
+	return requeue, nil
+}
+
+func (c *Controller) removeFinalizer(ctx context.Context, obj *unstructured.Unstructured) error {
+	var finalizers []string
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer != scales.ScaleTestFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+
+	obj.SetFinalizers(finalizers)
+	_, err := c.client.Resource(scales.ScaleTestResource).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func hasFinalizer(obj *unstructured.Unstructured) bool {
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer == scales.ScaleTestFinalizer {
+			return true
+		}
+	}
+	return false
+}
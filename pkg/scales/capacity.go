@@ -0,0 +1,266 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type CapacityPolicy string
+
+const (
+	// Scales without checking capacity, the default
+	CapacityIgnore CapacityPolicy = "ignore"
+	// Scales anyway, reporting the shortfall on the job
+	CapacityWarn CapacityPolicy = "warn"
+	// Refuses requests the cluster or a namespace quota cannot fit
+	CapacityRefuse CapacityPolicy = "refuse"
+)
+
+// Resources compared by the pre-flight check
+var capacityResources = []apiv1.ResourceName{apiv1.ResourceCPU, apiv1.ResourceMemory, apiv1.ResourcePods}
+
+// Requests added by raising the minimums, compared with what the cluster has left
+type CapacityReport struct {
+	Additional apiv1.ResourceList            `json:"additional"`        // Requests of the pods the new minimums add
+	Free       apiv1.ResourceList            `json:"free"`              // Allocatable left on schedulable nodes
+	Targets    map[string]apiv1.ResourceList `json:"targets,omitempty"` // Additional requests of every target
+	Unknown    map[string]string             `json:"unknown,omitempty"` // Targets that could not be estimated, not counted
+	Warnings   []string                      `json:"warnings,omitempty"`
+}
+
+// Whether every limit can fit the additional requests
+func (r CapacityReport) Sufficient() bool {
+	return len(r.Warnings) == 0
+}
+
+// Returned when the capacity policy refuses a request
+type CapacityError struct {
+	Report CapacityReport
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("not enough capacity: %s", strings.Join(e.Report.Warnings, "; "))
+}
+
+// Estimates the requests added by the new minimums, taken from the workload pod
+// templates, and compares them with free node capacity and namespace ResourceQuotas
+func (s *ScalesFacade) CheckCapacity(ctx context.Context, scaleConfigs ScaleConfigs) (CapacityReport, error) {
+	helper, scaleHelper, policy := s.scoped(ctx)
+	report := CapacityReport{
+		Additional: apiv1.ResourceList{},
+		Targets:    make(map[string]apiv1.ResourceList),
+		Unknown:    make(map[string]string),
+	}
+
+	namespaces := make(map[string]apiv1.ResourceList)
+	for name, config := range scaleConfigs {
+		config.Name = name
+		additional, err := s.additionalRequests(ctx, helper, scaleHelper, policy.Timeout, config)
+		if err != nil {
+			report.Unknown[name] = err.Error()
+			continue
+		}
+
+		report.Targets[name] = additional
+		addResources(report.Additional, additional)
+
+		namespace := config.targetNamespace()
+		if namespaces[namespace] == nil {
+			namespaces[namespace] = apiv1.ResourceList{}
+		}
+		addResources(namespaces[namespace], additional)
+	}
+
+	free, err := freeCapacity(ctx, helper, policy.Timeout)
+	if err != nil {
+		return report, err
+	}
+	report.Free = free
+
+	for _, name := range capacityResources {
+		needed, ok := report.Additional[name]
+		if available := free[name]; ok && needed.Cmp(available) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("cluster has %s %s free, %s needed", available.String(), name, needed.String()))
+		}
+	}
+
+	for namespace, additional := range namespaces {
+		quotas, err := helper.listResourceQuotasWithTimeout(ctx, namespace, policy.Timeout)
+		if err != nil {
+			return report, err
+		}
+		report.Warnings = append(report.Warnings, quotaShortfalls(namespace, quotas, additional)...)
+	}
+
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+// Requests of the pods a target needs to reach its new minimum
+func (s *ScalesFacade) additionalRequests(ctx context.Context, helper k8sHelperInterface, scaleHelper scaleTypeHelperInterface, timeout time.Duration, config ScaleConfig) (apiv1.ResourceList, error) {
+	if err := scaleHelper.IdentifyHpaType(ctx, &config); err != nil {
+		return nil, err
+	}
+
+	if config.relative() {
+		scaler, err := s.scalerFactory.getScaler(config.Type, helper, s.logger, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := scaler.CurrentConfig(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+
+		if config, err = config.resolveCurrent(current); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := helper.getTargetWithTimeout(ctx, config.Namespace, config.Kind, config.Target, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, found, err := unstructured.NestedInt64(target.Object, "spec", "replicas")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		replicas = 1
+	}
+
+	desired := config.Min
+	if config.Type == "Replicas" {
+		desired = config.targetReplicas()
+	}
+
+	additional := apiv1.ResourceList{}
+	pods := int64(desired) - replicas
+	if pods <= 0 {
+		return additional, nil
+	}
+
+	template, found, err := unstructured.NestedMap(target.Object, "spec", "template", "spec")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s/%s has no pod template", config.Kind, config.Namespace, config.Target)
+	}
+
+	var spec apiv1.PodSpec
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(template, &spec); err != nil {
+		return nil, err
+	}
+
+	for name, quantity := range podRequests(spec) {
+		additional[name] = *resource.NewMilliQuantity(quantity.MilliValue()*pods, quantity.Format)
+	}
+	additional[apiv1.ResourcePods] = *resource.NewQuantity(pods, resource.DecimalSI)
+	return additional, nil
+}
+
+// Allocatable of schedulable nodes minus the requests of the pods running on them
+func freeCapacity(ctx context.Context, helper k8sHelperInterface, timeout time.Duration) (apiv1.ResourceList, error) {
+	nodes, err := helper.listNodesWithTimeout(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	free := apiv1.ResourceList{}
+	schedulable := make(map[string]bool)
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		schedulable[node.Name] = true
+		addResources(free, node.Status.Allocatable)
+	}
+
+	pods, err := helper.listPodsWithTimeout(ctx, "", timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		if !schedulable[pod.Spec.NodeName] || pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+
+		used := podRequests(pod.Spec)
+		used[apiv1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+		for name, quantity := range used {
+			if available, ok := free[name]; ok {
+				available.Sub(quantity)
+				free[name] = available
+			}
+		}
+	}
+	return free, nil
+}
+
+// Limits of the namespace quotas the additional requests would exceed
+func quotaShortfalls(namespace string, quotas []apiv1.ResourceQuota, additional apiv1.ResourceList) []string {
+	// Quota resources constraining each compared resource
+	constrained := map[apiv1.ResourceName][]apiv1.ResourceName{
+		apiv1.ResourceCPU:    {apiv1.ResourceRequestsCPU, apiv1.ResourceCPU},
+		apiv1.ResourceMemory: {apiv1.ResourceRequestsMemory, apiv1.ResourceMemory},
+		apiv1.ResourcePods:   {apiv1.ResourcePods},
+	}
+
+	var warnings []string
+	for _, quota := range quotas {
+		for _, name := range capacityResources {
+			needed, ok := additional[name]
+			if !ok {
+				continue
+			}
+
+			for _, quotaName := range constrained[name] {
+				hard, ok := quota.Status.Hard[quotaName]
+				if !ok {
+					continue
+				}
+
+				left := hard.DeepCopy()
+				left.Sub(quota.Status.Used[quotaName])
+				if needed.Cmp(left) > 0 {
+					warnings = append(warnings, fmt.Sprintf("ResourceQuota %s/%s has %s %s left, %s needed", namespace, quota.Name, left.String(), quotaName, needed.String()))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// Requests of a pod: its containers added up, or its largest init container when higher
+func podRequests(spec apiv1.PodSpec) apiv1.ResourceList {
+	requests := apiv1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+
+	for _, container := range spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
+func addResources(total, added apiv1.ResourceList) {
+	for name, quantity := range added {
+		current := total[name]
+		current.Add(quantity)
+		total[name] = current
+	}
+}
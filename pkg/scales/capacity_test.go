@@ -0,0 +1,146 @@
+package scales
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newCapacityFacade(objects ...runtime.Object) *ScalesFacade {
+	replicas := int32(2)
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+		Spec: v1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{
+						Name: "api",
+						Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+							apiv1.ResourceCPU:    resource.MustParse("500m"),
+							apiv1.ResourceMemory: resource.MustParse("256Mi"),
+						}},
+					}},
+				},
+			},
+		},
+	}
+
+	objects = append(objects,
+		&apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: apiv1.NodeStatus{Allocatable: apiv1.ResourceList{
+				apiv1.ResourceCPU:    resource.MustParse("4"),
+				apiv1.ResourceMemory: resource.MustParse("8Gi"),
+				apiv1.ResourcePods:   resource.MustParse("110"),
+			}},
+		},
+		&apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "jobs"},
+			Spec: apiv1.PodSpec{
+				NodeName: "node-1",
+				Containers: []apiv1.Container{{
+					Name:      "batch",
+					Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("3")}},
+				}},
+			},
+			Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+		},
+	)
+
+	return newTestFacade(withClients(fake.NewSimpleClientset(objects...), dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)))
+}
+
+func TestCheckCapacity_Fits(t *testing.T) {
+	report, err := newCapacityFacade().CheckCapacity(context.TODO(), ScaleConfigs{"checkout": {Namespace: "shop", Min: 3, Max: 10}})
+
+	assert.Nil(t, err)
+	assert.True(t, report.Sufficient())
+	cpu := report.Additional[apiv1.ResourceCPU]
+	assert.Equal(t, "500m", cpu.String())
+	free := report.Free[apiv1.ResourceCPU]
+	assert.Equal(t, "1", free.String())
+}
+
+func TestCheckCapacity_RelativeReplicas(t *testing.T) {
+	report, err := newCapacityFacade().CheckCapacity(context.TODO(), ScaleConfigs{"checkout": {Namespace: "shop", MinChange: &RelativeChange{Factor: 2}}})
+
+	assert.Nil(t, err)
+	assert.Empty(t, report.Unknown)
+	assert.True(t, report.Sufficient())
+	cpu := report.Additional[apiv1.ResourceCPU]
+	assert.Equal(t, "1", cpu.String())
+}
+
+func TestCheckCapacity_ClusterAndQuotaShortfall(t *testing.T) {
+	quota := &apiv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "shop"},
+		Status: apiv1.ResourceQuotaStatus{
+			Hard: apiv1.ResourceList{apiv1.ResourcePods: resource.MustParse("5")},
+			Used: apiv1.ResourceList{apiv1.ResourcePods: resource.MustParse("3")},
+		},
+	}
+
+	report, err := newCapacityFacade(quota).CheckCapacity(context.TODO(), ScaleConfigs{
+		"checkout": {Namespace: "shop", Min: 6, Max: 10},
+		"missing":  {Namespace: "shop", Min: 6, Max: 10},
+	})
+
+	assert.Nil(t, err)
+	assert.False(t, report.Sufficient())
+	assert.Equal(t, []string{
+		"ResourceQuota shop/pods has 2 pods left, 4 needed",
+		"cluster has 1 cpu free, 2 needed",
+	}, report.Warnings)
+	assert.Contains(t, report.Unknown, "missing")
+}
+
+func TestSubmitJob_CapacityPolicy(t *testing.T) {
+	configs := ScaleConfigs{"checkout": {Namespace: "shop", Min: 6, Max: 10}}
+
+	_, err := newCapacityFacade().SubmitJob(context.TODO(), configs, UpdateOptions{CapacityPolicy: CapacityRefuse})
+	var capacityErr *CapacityError
+	assert.True(t, errors.As(err, &capacityErr))
+	assert.NotEmpty(t, capacityErr.Report.Warnings)
+
+	facade := newCapacityFacade()
+	facade.snapshots = newConfigMapSnapshotStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond)
+	job, err := facade.SubmitJob(context.TODO(), configs, UpdateOptions{CapacityPolicy: CapacityWarn})
+	assert.Nil(t, err)
+	assert.NotNil(t, job.Capacity)
+	assert.False(t, job.Capacity.Sufficient())
+}
+
+func TestSubmitJob_CapacityCheckFails(t *testing.T) {
+	configs := ScaleConfigs{"checkout": {Namespace: "shop", Min: 3, Max: 10}}
+	forbidNodes := func(facade *ScalesFacade) *ScalesFacade {
+		clientset := facade.k8sHelper.(*k8sHelper).clientset.(*fake.Clientset)
+		clientset.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(apiv1.Resource("nodes"), "", errors.New("namespace-scoped RBAC"))
+		})
+		facade.snapshots = newConfigMapSnapshotStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond)
+		return facade
+	}
+
+	_, err := forbidNodes(newCapacityFacade()).SubmitJob(context.TODO(), configs, UpdateOptions{CapacityPolicy: CapacityRefuse})
+	assert.True(t, apierrors.IsForbidden(err))
+
+	job, err := forbidNodes(newCapacityFacade()).SubmitJob(context.TODO(), configs, UpdateOptions{CapacityPolicy: CapacityWarn})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Nil(t, job.Capacity)
+}
@@ -0,0 +1,42 @@
+package scales
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// How to reach the kubernetes cluster
+type ClusterConfig struct {
+	// Path to a kubeconfig file, KUBECONFIG and ~/.kube/config are used when empty
+	Kubeconfig string
+	// Kubeconfig context, the current context is used when empty
+	Context string
+}
+
+// Uses the in-cluster config unless a kubeconfig or context is set,
+// falling back to the kubeconfig when not running inside a cluster
+func newRestConfig(cluster ClusterConfig) (*rest.Config, error) {
+	if cluster.Kubeconfig == "" && cluster.Context == "" {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			return config, nil
+		}
+
+		if err != rest.ErrNotInCluster {
+			return nil, fmt.Errorf("unable to load in-cluster config: %w", err)
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cluster.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cluster.Context}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+
+	return config, nil
+}
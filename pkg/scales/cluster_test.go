@@ -0,0 +1,62 @@
+package scales
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: staging
+  cluster:
+    server: https://staging.example.com
+- name: production
+  cluster:
+    server: https://production.example.com
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: tester
+- name: production
+  context:
+    cluster: production
+    user: tester
+current-context: staging
+users:
+- name: tester
+  user:
+    token: fake-token
+`
+
+func writeFakeKubeconfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(fakeKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewRestConfig_CurrentContext(t *testing.T) {
+	config, err := newRestConfig(ClusterConfig{Kubeconfig: writeFakeKubeconfig(t)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://staging.example.com", config.Host)
+}
+
+func TestNewRestConfig_ExplicitContext(t *testing.T) {
+	config, err := newRestConfig(ClusterConfig{Kubeconfig: writeFakeKubeconfig(t), Context: "production"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://production.example.com", config.Host)
+}
+
+func TestNewRestConfig_UnknownContext(t *testing.T) {
+	_, err := newRestConfig(ClusterConfig{Kubeconfig: writeFakeKubeconfig(t), Context: "missing"})
+
+	assert.NotNil(t, err)
+}
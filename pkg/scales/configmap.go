@@ -0,0 +1,101 @@
+package scales
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Document stored under a key of a ConfigMap, which is created on first write
+type configMapDocument struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+	key       string
+	timeout   time.Duration
+	mu        sync.Mutex
+}
+
+func newConfigMapDocument(clientset kubernetes.Interface, namespace, name, key string, timeout time.Duration) *configMapDocument {
+	return &configMapDocument{
+		clientset: clientset,
+		namespace: namespace,
+		name:      name,
+		key:       key,
+		timeout:   timeout,
+	}
+}
+
+// Returns the document, empty when the ConfigMap does not exist yet
+func (d *configMapDocument) load() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, data, err := d.read()
+	return data, err
+}
+
+// Replaces the document with the result of f, retrying f on conflicts
+func (d *configMapDocument) modify(f func(data string) (string, error)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, data, err := d.read()
+		if err != nil {
+			return err
+		}
+
+		if data, err = f(data); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		defer cancel()
+
+		if configMap == nil {
+			configMap = &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      d.name,
+					Namespace: d.namespace,
+				},
+				Data: map[string]string{d.key: data},
+			}
+			_, err = d.clientset.CoreV1().ConfigMaps(d.namespace).Create(ctx, configMap, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				return errors.NewConflict(apiv1.Resource("configmaps"), d.name, err)
+			}
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[d.key] = data
+		_, err = d.clientset.CoreV1().ConfigMaps(d.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Returns the ConfigMap (nil when it does not exist yet) and the document it holds
+func (d *configMapDocument) read() (*apiv1.ConfigMap, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	configMap, err := d.clientset.CoreV1().ConfigMaps(d.namespace).Get(ctx, d.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, "", nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return configMap, configMap.Data[d.key], nil
+}
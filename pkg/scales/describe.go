@@ -0,0 +1,154 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Live state of a scale target
+type TargetInfo struct {
+	Name         string         `json:"name"`
+	Namespace    string         `json:"namespace"`
+	Kind         string         `json:"kind"`
+	Type         string         `json:"type"`             // Scaler in charge: VanillaHpa, HpaOperator, Keda or Replicas
+	Object       string         `json:"object,omitempty"` // Object holding min and max
+	Hpa          string         `json:"hpa,omitempty"`
+	ScaledObject string         `json:"scaledObject,omitempty"`
+	Min          int            `json:"min"`
+	Max          int            `json:"max"`
+	Replicas     TargetReplicas `json:"replicas"`
+	LastModified *LastModified  `json:"lastModified,omitempty"` // Latest write to the object holding min and max
+}
+
+// Replica counts of the target workload
+type TargetReplicas struct {
+	Desired   int64 `json:"desired"` // Set on the workload spec
+	Current   int64 `json:"current"`
+	Ready     int64 `json:"ready"`
+	Available int64 `json:"available"`
+}
+
+// Latest write recorded in the managed fields of an object
+type LastModified struct {
+	Time      metav1.Time `json:"time"`
+	Manager   string      `json:"manager,omitempty"`
+	Operation string      `json:"operation,omitempty"`
+}
+
+// Reads the bounds, replicas and scaler of a target. An empty kind means Deployment.
+func (s *ScalesFacade) DescribeTarget(ctx context.Context, namespace, kind, name string) (TargetInfo, error) {
+	helper, scaleHelper, policy := s.scoped(ctx)
+	config, err := s.currentConfig(ctx, helper, scaleHelper, policy.Timeout, ScaleConfig{Name: name, Namespace: namespace, Kind: kind, Target: name})
+	if err != nil {
+		return TargetInfo{}, err
+	}
+
+	target, err := helper.getTargetWithTimeout(ctx, config.Namespace, config.Kind, config.Target, policy.Timeout)
+	if err != nil {
+		return TargetInfo{}, err
+	}
+
+	replicas, err := targetReplicas(target)
+	if err != nil {
+		return TargetInfo{}, err
+	}
+
+	// The target itself holds min and max for the operator and replicas scalers
+	modified := metav1.Object(target)
+	switch config.Type {
+	case "VanillaHpa":
+		if modified, err = helper.getHpaWithTimeout(ctx, config.Namespace, config.Hpa, policy.Timeout); err != nil {
+			return TargetInfo{}, err
+		}
+	case "Keda":
+		if modified, err = helper.getScaledObjectWithTimeout(ctx, config.Namespace, config.ScaledObject, policy.Timeout); err != nil {
+			return TargetInfo{}, err
+		}
+	}
+
+	return TargetInfo{
+		Name:         name,
+		Namespace:    config.Namespace,
+		Kind:         config.Kind,
+		Type:         config.Type,
+		Object:       mutatedObject(config),
+		Hpa:          config.Hpa,
+		ScaledObject: config.ScaledObject,
+		Min:          config.Min,
+		Max:          config.Max,
+		Replicas:     replicas,
+		LastModified: lastModified(modified),
+	}, nil
+}
+
+// Describes targets given as namespace/name, keyed the same way. Targets not found are skipped.
+func (s *ScalesFacade) DescribeTargets(ctx context.Context, kind string, targets ...string) (map[string]TargetInfo, error) {
+	infos := make(map[string]TargetInfo, len(targets))
+	for _, target := range targets {
+		namespace, name, err := splitTarget(target)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := s.DescribeTarget(ctx, namespace, kind, name)
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("%s not found: %s\n", target, err)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		infos[target] = info
+	}
+	return infos, nil
+}
+
+func splitTarget(target string) (string, string, error) {
+	parts := strings.Split(target, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.NewBadRequest(fmt.Sprintf("invalid target %q, expected namespace/name", target))
+	}
+	return parts[0], parts[1], nil
+}
+
+func targetReplicas(target *unstructured.Unstructured) (TargetReplicas, error) {
+	var replicas TargetReplicas
+	for _, field := range []struct {
+		value *int64
+		path  []string
+	}{
+		{&replicas.Desired, []string{"spec", "replicas"}},
+		{&replicas.Current, []string{"status", "replicas"}},
+		{&replicas.Ready, []string{"status", "readyReplicas"}},
+		{&replicas.Available, []string{"status", "availableReplicas"}},
+	} {
+		value, _, err := unstructured.NestedInt64(target.Object, field.path...)
+		if err != nil {
+			return replicas, err
+		}
+		*field.value = value
+	}
+	return replicas, nil
+}
+
+// Latest managed fields entry of the object, its creation when it has none
+func lastModified(object metav1.Object) *LastModified {
+	var latest *LastModified
+	for _, entry := range object.GetManagedFields() {
+		if entry.Time == nil || (latest != nil && !latest.Time.Before(entry.Time)) {
+			continue
+		}
+		latest = &LastModified{Time: *entry.Time, Manager: entry.Manager, Operation: string(entry.Operation)}
+	}
+
+	if created := object.GetCreationTimestamp(); latest == nil && !created.IsZero() {
+		latest = &LastModified{Time: created, Operation: "Create"}
+	}
+	return latest
+}
@@ -0,0 +1,120 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newDescribeFacade() *ScalesFacade {
+	replicas := int32(4)
+	minReplicas := int32(2)
+	modifiedAt := metav1.NewTime(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+
+	checkout := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+		Spec:       v1.DeploymentSpec{Replicas: &replicas},
+		Status:     v1.DeploymentStatus{Replicas: 4, ReadyReplicas: 3, AvailableReplicas: 3},
+	}
+	cart := &v1.Deployment{
+		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cart",
+			Namespace:   "shop",
+			Annotations: map[string]string{hpaOpMinAnnotation: "3", hpaOpMaxAnnotation: "9"},
+		},
+	}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "checkout-hpa",
+			Namespace: "shop",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply, Time: &modifiedAt},
+			},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout", APIVersion: "apps/v1"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+		},
+	}
+
+	// Created by the operator, without minReplicas, and not authoritative
+	operatorHpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "cart", APIVersion: "apps/v1"},
+			MaxReplicas:    5,
+		},
+	}
+
+	return newTestFacade(withClients(fake.NewSimpleClientset(hpa, operatorHpa), dynamicfake.NewSimpleDynamicClient(scheme.Scheme, checkout, cart)))
+}
+
+func TestDescribeTarget_VanillaHpa(t *testing.T) {
+	info, err := newDescribeFacade().DescribeTarget(context.TODO(), "shop", "", "checkout")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "VanillaHpa", info.Type)
+	assert.Equal(t, "Deployment", info.Kind)
+	assert.Equal(t, "checkout-hpa", info.Hpa)
+	assert.Equal(t, 2, info.Min)
+	assert.Equal(t, 10, info.Max)
+	assert.Equal(t, TargetReplicas{Desired: 4, Current: 4, Ready: 3, Available: 3}, info.Replicas)
+	assert.NotNil(t, info.LastModified)
+	assert.Equal(t, "kubectl", info.LastModified.Manager)
+}
+
+func TestDescribeTarget_HpaOperator(t *testing.T) {
+	info, err := newDescribeFacade().DescribeTarget(context.TODO(), "shop", "Deployment", "cart")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "HpaOperator", info.Type)
+	assert.Equal(t, 3, info.Min)
+	assert.Equal(t, 9, info.Max)
+}
+
+func TestDescribeTargets(t *testing.T) {
+	facade := newDescribeFacade()
+
+	infos, err := facade.DescribeTargets(context.TODO(), "", "shop/checkout", "shop/missing")
+	assert.Nil(t, err)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "checkout", infos["shop/checkout"].Name)
+
+	_, err = facade.DescribeTargets(context.TODO(), "", "checkout")
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestGetHpaInfo_ReadsAuthoritativeSource(t *testing.T) {
+	facade := newDescribeFacade()
+
+	configs, err := facade.GetHpaInfo(context.TODO(), ScaleConfigs{
+		"checkout": {Namespace: "shop", Min: 30, Max: 50},
+		"cart":     {Namespace: "shop", MinChange: &RelativeChange{Factor: 2}},
+		"missing":  {Namespace: "shop"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+
+	assert.Equal(t, "VanillaHpa", configs["checkout"].Type)
+	assert.Equal(t, "checkout-hpa", configs["checkout"].Hpa)
+	assert.Equal(t, 2, configs["checkout"].Min)
+	assert.Equal(t, 10, configs["checkout"].Max)
+
+	assert.Equal(t, "HpaOperator", configs["cart"].Type)
+	assert.True(t, configs["cart"].HpaOperator)
+	assert.Equal(t, 3, configs["cart"].Min)
+	assert.Equal(t, 9, configs["cart"].Max)
+	assert.Nil(t, configs["cart"].MinChange)
+}
@@ -2,116 +2,551 @@ package scales
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 type ScalesFacade struct {
-	scaleHelper   scaleTypeHelperInterface
 	k8sHelper     k8sHelperInterface
 	scalerFactory *scalerFactory
+	snapshots     snapshotStore
+	profiles      profileStore
+	schedules     scheduleStore
+	scheduler     *scheduler
+	jobs          *JobStore
+	clientset     kubernetes.Interface
+	dynamic       dynamic.Interface
+	policy        Policy
 	logger        *logrus.Logger
 }
 
-func NewScalesFacade(logger *logrus.Logger) *ScalesFacade {
-	k8sHelper := newK8sHelper()
+// Unset policy fields fall back to DefaultPolicy
+func NewScalesFacade(logger *logrus.Logger, cluster ClusterConfig, policy Policy) (*ScalesFacade, error) {
+	config, err := newRestConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sHelper, err := newK8sHelper(config)
+	if err != nil {
+		return nil, err
+	}
+
+	policy = DefaultPolicy().withOverride(policy)
 	return &ScalesFacade{
-		scaleHelper:   newScaleTypeHelper(k8sHelper, logger, 500),
 		k8sHelper:     k8sHelper,
 		scalerFactory: &scalerFactory{},
+		snapshots:     newConfigMapSnapshotStore(k8sHelper.clientset, currentNamespace(), policy.Timeout),
+		profiles:      newConfigMapProfileStore(k8sHelper.clientset, currentNamespace(), policy.Timeout),
+		schedules:     newConfigMapScheduleStore(k8sHelper.clientset, currentNamespace(), policy.Timeout),
+		scheduler:     newScheduler(),
+		jobs:          NewJobStore(),
+		clientset:     k8sHelper.clientset,
+		dynamic:       k8sHelper.dynamic,
+		policy:        policy,
 		logger:        logger,
-	}
+	}, nil
 }
 
-// TODO: Refactor
-// Returns a new clientset for internal use
-func (s *ScalesFacade) GetClientset() (kubernetes.Interface, error) {
-	config, err := rest.InClusterConfig()
+// Returns the helpers for calls made under ctx, with the policy carried by ctx
+// taking precedence over the facade one
+func (s *ScalesFacade) scoped(ctx context.Context) (k8sHelperInterface, scaleTypeHelperInterface, Policy) {
+	policy := DefaultPolicy().withOverride(s.policy).withOverride(policyFrom(ctx))
+	helper := s.k8sHelper.withPolicy(policy)
+	return helper, newScaleTypeHelper(helper, s.logger, policy.Timeout), policy
+}
 
-	if err != nil {
-		return nil, err
+// Returns the clientset shared by the facade
+func (s *ScalesFacade) GetClientset() (kubernetes.Interface, error) {
+	if s.clientset == nil {
+		return nil, fmt.Errorf("facade has no kubernetes clientset")
 	}
+	return s.clientset, nil
+}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, err
+// Returns the dynamic client shared by the facade
+func (s *ScalesFacade) GetDynamicClient() (dynamic.Interface, error) {
+	if s.dynamic == nil {
+		return nil, fmt.Errorf("facade has no kubernetes dynamic client")
 	}
-
-	client := kubernetes.Interface(clientset)
-	return client, nil
+	return s.dynamic, nil
 }
 
-// TODO: Refactor
-func (s *ScalesFacade) GetHpaInfo(clientset kubernetes.Interface, scaleConfigs ScaleConfigs, logger *logrus.Logger) (ScaleConfigs, error) {
+// Returns the live min and max of every target, read from the object its scaler controls:
+// the HPA for VanillaHpa, the workload annotations for HpaOperator, the ScaledObject for Keda
+// and the workload replicas for Replicas. Type, Hpa and ScaledObject tell which one was read.
+// Targets not found are skipped.
+func (s *ScalesFacade) GetHpaInfo(ctx context.Context, scaleConfigs ScaleConfigs) (ScaleConfigs, error) {
 	currentConfig := make(ScaleConfigs)
-	for name := range scaleConfigs {
-		hpa, err := clientset.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	helper, scaleHelper, policy := s.scoped(ctx)
+	for name, config := range scaleConfigs {
+		config.Name = name
+		current, err := s.currentConfig(ctx, helper, scaleHelper, policy.Timeout, config)
+
 		if errors.IsForbidden(err) || errors.IsUnauthorized(err) {
-			logger.Errorln(err.Error())
+			s.logger.Errorln(err.Error())
 			return nil, err
 		}
 
 		if errors.IsNotFound(err) {
-			logger.Warnf("HPA not found in namespace %s\n", name)
+			s.logger.Warnf("%s not found in namespace %s: %s\n", name, config.targetNamespace(), err)
 			continue
 		}
 
-		currentConfig[hpa.Name] = ScaleConfig{
-			Min: int(*hpa.Spec.MinReplicas),
-			Max: int(hpa.Spec.MaxReplicas),
+		if err != nil {
+			return nil, err
+		}
+
+		// Only live values are reported, not the requested changes
+		current = current.absolute()
+		if current.Type != "Replicas" {
+			current.Replicas = nil
 		}
+		currentConfig[name] = current
 	}
 	return currentConfig, nil
 }
 
-// Update HPA list
-func (s *ScalesFacade) UpdateWithConcurrency(scaleConfigs ScaleConfigs, sleep *time.Duration) {
-	scaleCh := make(chan ScaleConfig)
-	errorCh := make(chan error)
+// Identifies the scaler of a target and reads min and max from the object it controls
+func (s *ScalesFacade) currentConfig(ctx context.Context, helper k8sHelperInterface, scaleHelper scaleTypeHelperInterface, timeout time.Duration, config ScaleConfig) (ScaleConfig, error) {
+	if err := scaleHelper.IdentifyHpaType(ctx, &config); err != nil {
+		return config, err
+	}
+
+	scaler, err := s.scalerFactory.getScaler(config.Type, helper, s.logger, timeout)
+	if err != nil {
+		return config, err
+	}
+
+	return scaler.CurrentConfig(ctx, config)
+}
+
+// Options of a scale request
+type UpdateOptions struct {
+	// Pause between starting to scale two targets
+	Sleep time.Duration
+	// Targets scaled at the same time, one at a time when zero
+	Concurrency int
+	// API requests per second sent by the job, unlimited when zero
+	QPS float32
+	// Requests sent at once before QPS applies, one when zero
+	Burst int
+	// Share of the targets started per wave, from 1 to 100, every target at once when zero
+	WavePercent int
+	// Time between the start of two waves
+	WaveInterval time.Duration
+	// Restores the original configs once elapsed, disabled when zero
+	TTL time.Duration
+	// What to do when the new minimums may not fit, ignore when empty
+	CapacityPolicy CapacityPolicy
+	// Waits up to this long after scaling a target for its minimum to be available, disabled when zero
+	WaitForReady time.Duration
+}
+
+// Checks capacity as the policy says, then starts updating the HPA list in background and
+// returns the job tracking it. The job outlives ctx, only the policy it carries is kept,
+// and stops on CancelJob. A refused request returns a *CapacityError, only the refuse
+// policy fails when capacity cannot be checked.
+func (s *ScalesFacade) SubmitJob(ctx context.Context, scaleConfigs ScaleConfigs, options UpdateOptions) (Job, error) {
+	if err := options.validate(); err != nil {
+		return Job{}, err
+	}
+
+	var capacity *CapacityReport
+	if options.CapacityPolicy == CapacityWarn || options.CapacityPolicy == CapacityRefuse {
+		report, err := s.CheckCapacity(ctx, scaleConfigs)
+		switch {
+		case err != nil && options.CapacityPolicy == CapacityRefuse:
+			return Job{}, fmt.Errorf("unable to check capacity: %w", err)
+		case err != nil:
+			// Warnings never block, a check the cluster cannot answer is skipped
+			s.logger.Warnf("Scaling without capacity check: %s\n", err)
+		case !report.Sufficient() && options.CapacityPolicy == CapacityRefuse:
+			return Job{}, &CapacityError{Report: report}
+		default:
+			if !report.Sufficient() {
+				s.logger.Warnf("Scaling despite capacity warnings: %s\n", strings.Join(report.Warnings, "; "))
+			}
+			capacity = &report
+		}
+	}
+
+	jobCtx, cancel := context.WithCancel(WithPolicy(context.Background(), policyFrom(ctx)))
+	job := s.jobs.create(scaleConfigs, cancel)
+	if capacity != nil {
+		job = s.jobs.setCapacity(job.ID, capacity)
+	}
+
+	go func() {
+		s.updateWithConcurrency(jobCtx, job.ID, scaleConfigs, options)
+		if options.TTL > 0 {
+			if err := s.ScheduleRestore(scaleConfigs, options.TTL); err != nil {
+				s.logger.Errorf("Unable to schedule restore: %s\n", err)
+			}
+		}
+	}()
+
+	return job, nil
+}
+
+func (s *ScalesFacade) GetJob(id string) (Job, bool) {
+	return s.jobs.Get(id)
+}
+
+func (s *ScalesFacade) ListJobs() []Job {
+	return s.jobs.List()
+}
+
+// Stops the pending targets of a job and waits, as long as ctx allows, for the
+// job to finish. Targets already scaled are not reverted.
+func (s *ScalesFacade) CancelJob(ctx context.Context, id string) (Job, bool) {
+	done, ok := s.jobs.cancel(id)
+	if !ok {
+		return Job{}, false
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	return s.jobs.Get(id)
+}
+
+// Waits, as long as ctx allows, for a job to finish and returns it.
+// The job keeps running when ctx is done first.
+func (s *ScalesFacade) WaitJob(ctx context.Context, id string) (Job, bool) {
+	done, ok := s.jobs.done(id)
+	if !ok {
+		return Job{}, false
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	return s.jobs.Get(id)
+}
+
+// Update HPA list, returning once every target is done. The job reports the outcome
+// and timings of every target, the error lists the targets that did not scale.
+// A nil sleep scales the targets without pausing.
+func (s *ScalesFacade) UpdateWithConcurrency(ctx context.Context, scaleConfigs ScaleConfigs, sleep *time.Duration) (Job, error) {
+	var options UpdateOptions
+	if sleep != nil {
+		options.Sleep = *sleep
+	}
+
+	job := s.RunJob(ctx, scaleConfigs, options)
+	return job, job.Err()
+}
+
+// Scales the targets and returns the finished job tracking them, the TTL option is ignored
+func (s *ScalesFacade) RunJob(ctx context.Context, scaleConfigs ScaleConfigs, options UpdateOptions) Job {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	job := s.jobs.create(scaleConfigs, cancel)
+	s.updateWithConcurrency(ctx, job.ID, scaleConfigs, options)
+
+	job, _ = s.jobs.Get(job.ID)
+	return job
+}
+
+func (s *ScalesFacade) updateWithConcurrency(ctx context.Context, jobID string, scaleConfigs ScaleConfigs, options UpdateOptions) {
+	// Buffered so identification never blocks once the job is canceled
+	scaleCh := make(chan ScaleConfig, len(scaleConfigs))
+	errorCh := make(chan error, len(scaleConfigs))
+
+	// Targets are watched while the next ones scale, the job finishes once all are ready
+	var ready sync.WaitGroup
+	defer func() {
+		ready.Wait()
+		s.jobs.finish(jobID)
+	}()
 
 	// Checks if it is Hpa Operator
-	scaleHelper := s.scaleHelper
+	helper, _, policy := s.scoped(ctx)
+	if limiter := options.rateLimiter(); limiter != nil {
+		defer limiter.Stop()
+		helper = helper.withRateLimiter(limiter)
+	}
+	// Identification shares the worker bound and lists the HPAs of each namespace once
+	scaleHelper := newScaleTypeHelper(helper.withHpaCache(), s.logger, policy.Timeout)
+	identifying := make(chan struct{}, options.workers(len(scaleConfigs)))
 	for scaleName, scaleConfig := range scaleConfigs {
 		scaleConfig.Name = scaleName
 		go func(config ScaleConfig) {
-			err := scaleHelper.IdentifyHpaType(&config)
+			identifying <- struct{}{}
+			err := scaleHelper.IdentifyHpaType(ctx, &config)
+			<-identifying
+
+			if err != nil && ctx.Err() != nil {
+				s.jobs.setTarget(jobID, config.Name, TargetCanceled, config.Type, "canceled before scaling")
+				errorCh <- err
+				return
+			}
 
 			if err != nil {
 				s.logger.Warnf(err.Error())
+				s.jobs.setTarget(jobID, config.Name, TargetFailed, config.Type, err.Error())
 				errorCh <- err
 				return
 			}
 
+			s.jobs.setTarget(jobID, config.Name, TargetIdentified, config.Type, "")
 			scaleCh <- config
 			s.logger.Debugf("%s config sent.\n", config.Name)
 		}(scaleConfig)
 	}
 
+	work := make(chan ScaleConfig)
+	var workers sync.WaitGroup
+	for i := 0; i < options.workers(len(scaleConfigs)); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for config := range work {
+				s.scaleTarget(ctx, helper, policy, jobID, config, options, &ready)
+			}
+		}()
+	}
+
+	pacer := newPacer(options, len(scaleConfigs))
 	for i := 0; i < len(scaleConfigs); i++ {
 		select {
-		case configs := <-scaleCh:
-			s.logger.Debugf("%s config received.\n", configs.Name)
-			scaler, err := s.scalerFactory.getScaler(configs.Type, s.k8sHelper, s.logger)
-
-			if err != nil {
-				s.logger.Errorln(err)
-				return
+		case config := <-scaleCh:
+			s.logger.Debugf("%s config received.\n", config.Name)
+			if err := pacer.wait(ctx); err != nil {
+				s.jobs.setTarget(jobID, config.Name, TargetCanceled, config.Type, "canceled before scaling")
+				continue
 			}
+			work <- config
+		case err := <-errorCh:
+			s.logger.Errorln(err.Error())
+		}
+	}
 
-			err = scaler.Scale(configs)
-			if err != nil {
-				s.logger.Errorln(err)
+	close(work)
+	workers.Wait()
+}
+
+// Snapshots and scales an identified target, then starts waiting for it to be ready when asked to
+func (s *ScalesFacade) scaleTarget(ctx context.Context, helper k8sHelperInterface, policy Policy, jobID string, config ScaleConfig, options UpdateOptions, ready *sync.WaitGroup) {
+	if ctx.Err() != nil {
+		s.jobs.setTarget(jobID, config.Name, TargetCanceled, config.Type, "canceled before scaling")
+		return
+	}
+
+	scaler, err := s.scalerFactory.getScaler(config.Type, helper, s.logger, policy.Timeout)
+	if err != nil {
+		s.logger.Errorln(err)
+		s.jobs.setTarget(jobID, config.Name, TargetFailed, config.Type, err.Error())
+		return
+	}
+
+	start := time.Now()
+	previous, err := scaler.CurrentConfig(ctx, config)
+	if err != nil {
+		s.logger.Errorf("Skipping %s, unable to read current config: %s\n", config.Name, err)
+		s.jobs.setTarget(jobID, config.Name, TargetFailed, config.Type, fmt.Sprintf("unable to read current config: %s", err))
+		return
+	}
+
+	if err = s.recordSnapshot(config, previous); err != nil {
+		s.logger.Errorf("Skipping %s, unable to snapshot original config: %s\n", config.Name, err)
+		s.jobs.setTarget(jobID, config.Name, TargetFailed, config.Type, fmt.Sprintf("unable to snapshot original config: %s", err))
+		return
+	}
+
+	applied, err := scaler.Scale(ctx, config)
+	if err != nil && ctx.Err() != nil {
+		s.logger.Warnf("%s canceled while scaling, it may have changed.\n", config.Name)
+		s.jobs.setTarget(jobID, config.Name, TargetCanceled, config.Type, fmt.Sprintf("canceled while scaling, it may have changed: %s", err))
+		return
+	}
+
+	if err != nil {
+		s.logger.Errorf("Unable to scale %s: %s\n", config.Name, err)
+		s.jobs.setTarget(jobID, config.Name, TargetFailed, config.Type, err.Error())
+		return
+	}
+
+	s.logger.Infof("%s scaled to min %d and max %d.\n", config.Name, applied.Min, applied.Max)
+	s.jobs.setScaled(jobID, config.Name, config.Type, boundsOf(previous), boundsOf(applied), time.Since(start))
+
+	if options.WaitForReady > 0 {
+		ready.Add(1)
+		go func() {
+			defer ready.Done()
+			s.waitForReady(ctx, helper, jobID, applied, options.WaitForReady)
+		}()
+	}
+}
+
+// Watches a scaled target until its minimum is available, recording how long it took
+func (s *ScalesFacade) waitForReady(ctx context.Context, helper k8sHelperInterface, jobID string, config ScaleConfig, timeout time.Duration) {
+	desired := config.Min
+	if config.Type == "Replicas" {
+		desired = config.targetReplicas()
+	}
+
+	start := time.Now()
+	err := helper.waitForAvailableWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), int32(desired), timeout)
+	if err != nil {
+		s.logger.Warnf("%s not ready: %s\n", config.Name, err)
+		s.jobs.setReady(jobID, config.Name, 0, err)
+		return
+	}
+
+	elapsed := time.Since(start)
+	s.logger.Infof("%s ready with %d replicas after %s.\n", config.Name, desired, elapsed.Round(time.Millisecond))
+	s.jobs.setReady(jobID, config.Name, elapsed, nil)
+}
+
+// Stores the original config of a target under its identity, unless a test is already holding it.
+// The original is read by the caller, keeping live reads out of the store's lock.
+func (s *ScalesFacade) recordSnapshot(config, original ScaleConfig) error {
+	key := config.targetKey()
+	return s.snapshots.update(func(snapshots Snapshots) error {
+		if _, ok := snapshots[key]; ok {
+			s.logger.Debugf("%s already has a snapshot, keeping it.\n", config.Name)
+			return nil
+		}
+
+		// Restoring must write the live values back, not apply the request's changes again
+		snapshots[key] = Snapshot{
+			Original: original.absolute(),
+			TakenAt:  time.Now(),
+		}
+		return nil
+	})
+}
+
+// Returns the stored original configs
+func (s *ScalesFacade) GetSnapshots() (Snapshots, error) {
+	return s.snapshots.list()
+}
+
+// Restores the original configs of the targets given by their namespace/kind/name keys, or of
+// every snapshot when none is given. Snapshots are removed once their target is restored.
+func (s *ScalesFacade) Restore(ctx context.Context, keys ...string) (ScaleConfigs, error) {
+	snapshots, err := s.snapshots.list()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		for key := range snapshots {
+			keys = append(keys, key)
+		}
+	}
+
+	helper, _, policy := s.scoped(ctx)
+	var errs []error
+	restored := make(ScaleConfigs)
+	for _, key := range keys {
+		snapshot, ok := snapshots[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("no snapshot found for %s", key))
+			continue
+		}
+
+		scaler, err := s.scalerFactory.getScaler(snapshot.Original.Type, helper, s.logger, policy.Timeout)
+		if err == nil {
+			_, err = scaler.Scale(ctx, snapshot.Original.absolute())
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to restore %s: %w", key, err))
+			continue
+		}
+
+		s.logger.Infof("%s restored to min %d and max %d.\n", key, snapshot.Original.Min, snapshot.Original.Max)
+		restored[key] = snapshot.Original
+	}
+
+	err = s.snapshots.update(func(snapshots Snapshots) error {
+		for key := range restored {
+			delete(snapshots, key)
+		}
+		return nil
+	})
+
+	return restored, utilerrors.NewAggregate(append(errs, err))
+}
+
+// Marks the snapshots of the given targets to be restored after ttl
+func (s *ScalesFacade) ScheduleRestore(scaleConfigs ScaleConfigs, ttl time.Duration) error {
+	restoreAt := time.Now().Add(ttl)
+	err := s.snapshots.update(func(snapshots Snapshots) error {
+		for _, key := range scaleConfigs.targetKeys() {
+			if snapshot, ok := snapshots[key]; ok {
+				snapshot.RestoreAt = &restoreAt
+				snapshots[key] = snapshot
 			}
+		}
+		return nil
+	})
 
-			time.Sleep(*sleep)
-		case err := <-errorCh:
-			s.logger.Errorln(err.Error())
+	if err != nil {
+		return err
+	}
+
+	s.armRestore(restoreAt)
+	return nil
+}
+
+// Arms restores persisted by a previous run
+func (s *ScalesFacade) ResumeRestores() error {
+	snapshots, err := s.snapshots.list()
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.RestoreAt != nil {
+			s.armRestore(*snapshot.RestoreAt)
+		}
+	}
+	return nil
+}
+
+func (s *ScalesFacade) armRestore(restoreAt time.Time) {
+	time.AfterFunc(time.Until(restoreAt), s.restoreDue)
+}
+
+// Restores every snapshot whose restore time has passed
+func (s *ScalesFacade) restoreDue() {
+	snapshots, err := s.snapshots.list()
+	if err != nil {
+		s.logger.Errorln(err)
+		return
+	}
+
+	var due []string
+	now := time.Now()
+	for key, snapshot := range snapshots {
+		if snapshot.RestoreAt != nil && !snapshot.RestoreAt.After(now) {
+			due = append(due, key)
 		}
 	}
+
+	if len(due) == 0 {
+		return
+	}
+
+	if _, err = s.Restore(context.Background(), due...); err != nil {
+		s.logger.Errorln(err)
+	}
 }
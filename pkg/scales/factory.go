@@ -2,6 +2,7 @@ package scales
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
@@ -11,14 +12,21 @@ type scalerFactoryInterface interface {
 	getScaler(scalerType string, clientset kubernetes.Interface, logger *logrus.Logger) (scaler, error)
 }
 
+// Types set by identification, each handled by its own scaler
+var scalerTypes = []string{"VanillaHpa", "HpaOperator", "Keda", "Replicas"}
+
 type scalerFactory struct{}
 
-func (s *scalerFactory) getScaler(scalerType string, k8sHelper k8sHelperInterface, logger *logrus.Logger) (scaler, error) {
+func (s *scalerFactory) getScaler(scalerType string, k8sHelper k8sHelperInterface, logger *logrus.Logger, timeout time.Duration) (scaler, error) {
 	switch scalerType {
 	case "VanillaHpa":
-		return newVanillaHpa(k8sHelper, logger), nil
+		return newVanillaHpa(k8sHelper, logger, timeout), nil
 	case "HpaOperator":
-		return newHpaOperator(k8sHelper, logger), nil
+		return newHpaOperator(k8sHelper, logger, timeout), nil
+	case "Keda":
+		return newKedaScaler(k8sHelper, logger, timeout), nil
+	case "Replicas":
+		return newReplicaScaler(k8sHelper, logger, timeout), nil
 	default:
 		return nil, fmt.Errorf("Not valid scaler type")
 	}
@@ -0,0 +1,339 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// Number of jobs kept in memory, oldest finished jobs are dropped first
+const maxJobs = 100
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+type TargetStatus string
+
+const (
+	TargetPending    TargetStatus = "pending"
+	TargetIdentified TargetStatus = "identified"
+	TargetScaled     TargetStatus = "scaled"
+	TargetFailed     TargetStatus = "failed"
+	TargetCanceled   TargetStatus = "canceled"
+)
+
+// Progress of a scale request
+type Job struct {
+	ID         string                `json:"id"`
+	Status     JobStatus             `json:"status"`
+	Targets    map[string]*JobTarget `json:"targets"`
+	StartedAt  time.Time             `json:"startedAt"`
+	FinishedAt *time.Time            `json:"finishedAt,omitempty"`
+	Capacity   *CapacityReport       `json:"capacity,omitempty"` // Pre-flight check, when the capacity policy asked for one
+}
+
+// Progress of a single target of a job
+type JobTarget struct {
+	Status      TargetStatus     `json:"status"`
+	Type        string           `json:"type,omitempty"`
+	Reason      string           `json:"reason,omitempty"`
+	Previous    *ScaleBounds     `json:"previous,omitempty"`    // Bounds the target had before scaling
+	Applied     *ScaleBounds     `json:"applied,omitempty"`     // Absolute bounds set on the target, relative changes resolved
+	ScaledIn    *metav1.Duration `json:"scaledIn,omitempty"`    // From reading the current config to the scaler returning
+	Ready       *bool            `json:"ready,omitempty"`       // Whether the minimum became available, set when waiting for it
+	TimeToReady *metav1.Duration `json:"timeToReady,omitempty"` // From scaling to the minimum being available
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// Names of the targets already scaled, sorted
+func (j Job) Changed() []string {
+	changed := []string{}
+	for name, target := range j.Targets {
+		if target.Status == TargetScaled {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// Errors of the targets that did not scale or become ready, sorted by target, nil when there are none
+func (j Job) Err() error {
+	names := make([]string, 0, len(j.Targets))
+	for name := range j.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		target := j.Targets[name]
+		if target.Status != TargetScaled || (target.Ready != nil && !*target.Ready) {
+			errs = append(errs, fmt.Errorf("%s %s: %s", name, target.Status, target.Reason))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Controls a job until it finishes
+type jobRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Keeps jobs in memory, safe for concurrent use
+type JobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+	runs map[string]*jobRun
+}
+
+func NewJobStore() *JobStore {
+	return &JobStore{
+		jobs: make(map[string]*Job),
+		runs: make(map[string]*jobRun),
+	}
+}
+
+// Creates a running job, cancel stops it
+func (s *JobStore) create(scaleConfigs ScaleConfigs, cancel context.CancelFunc) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	job := &Job{
+		ID:        rand.String(10),
+		Status:    JobRunning,
+		Targets:   make(map[string]*JobTarget),
+		StartedAt: now,
+	}
+
+	for name := range scaleConfigs {
+		job.Targets[name] = &JobTarget{
+			Status:    TargetPending,
+			UpdatedAt: now,
+		}
+	}
+
+	s.prune()
+	s.jobs[job.ID] = job
+	s.runs[job.ID] = &jobRun{cancel: cancel, done: make(chan struct{})}
+	return job.copy()
+}
+
+// Returns a channel closed once the job finishes
+func (s *JobStore) done(id string) (<-chan struct{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return nil, false
+	}
+
+	run, ok := s.runs[id]
+	if !ok {
+		done := make(chan struct{})
+		close(done)
+		return done, true
+	}
+	return run.done, true
+}
+
+// Cancels a running job, the returned channel is closed once the job finishes
+func (s *JobStore) cancel(id string) (<-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return nil, false
+	}
+
+	run, ok := s.runs[id]
+	if !ok {
+		done := make(chan struct{})
+		close(done)
+		return done, true
+	}
+
+	if run.cancel != nil {
+		run.cancel()
+	}
+	return run.done, true
+}
+
+// Returns a copy of the job
+func (s *JobStore) Get(id string) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.copy(), true
+}
+
+// Returns copies of every job, most recent first
+func (s *JobStore) List() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.copy())
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+func (s *JobStore) setTarget(id, name string, status TargetStatus, scalerType, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	job.Targets[name] = &JobTarget{
+		Status:    status,
+		Type:      scalerType,
+		Reason:    reason,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Attaches the pre-flight capacity report to a job, returning the updated job
+func (s *JobStore) setCapacity(id string, report *CapacityReport) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}
+	}
+
+	job.Capacity = report
+	return job.copy()
+}
+
+// Marks a target as scaled, recording the bounds it had and the ones it was given
+func (s *JobStore) setScaled(id, name, scalerType string, previous, applied ScaleBounds, took time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	job.Targets[name] = &JobTarget{
+		Status:    TargetScaled,
+		Type:      scalerType,
+		Previous:  &previous,
+		Applied:   &applied,
+		ScaledIn:  &metav1.Duration{Duration: took},
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Records whether a scaled target became ready, and how long it took
+func (s *JobStore) setReady(id, name string, after time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	target, ok := job.Targets[name]
+	if !ok {
+		return
+	}
+
+	ready := err == nil
+	target.Ready = &ready
+	target.UpdatedAt = time.Now()
+	if err != nil {
+		target.Reason = fmt.Sprintf("not ready: %s", err)
+		return
+	}
+	target.TimeToReady = &metav1.Duration{Duration: after}
+}
+
+// Marks the job as finished, canceled when any target was canceled
+// and failed when any other target did not scale or become ready
+func (s *JobStore) finish(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if run, ok := s.runs[id]; ok {
+		if run.cancel != nil {
+			run.cancel()
+		}
+		close(run.done)
+		delete(s.runs, id)
+	}
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = JobSucceeded
+	for _, target := range job.Targets {
+		if target.Status == TargetCanceled {
+			job.Status = JobCanceled
+			break
+		}
+		if target.Status != TargetScaled || (target.Ready != nil && !*target.Ready) {
+			job.Status = JobFailed
+		}
+	}
+}
+
+// Drops the oldest finished jobs when the store is full. Must hold the lock.
+func (s *JobStore) prune() {
+	for len(s.jobs) >= maxJobs {
+		var oldest *Job
+		for _, job := range s.jobs {
+			if job.FinishedAt != nil && (oldest == nil || job.StartedAt.Before(oldest.StartedAt)) {
+				oldest = job
+			}
+		}
+
+		if oldest == nil {
+			return
+		}
+		delete(s.jobs, oldest.ID)
+	}
+}
+
+func (j *Job) copy() Job {
+	job := *j
+	job.Targets = make(map[string]*JobTarget, len(j.Targets))
+	for name, target := range j.Targets {
+		t := *target
+		job.Targets[name] = &t
+	}
+	return job
+}
@@ -0,0 +1,196 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestJobStore_Finish(t *testing.T) {
+	store := NewJobStore()
+	job := store.create(ScaleConfigs{"some-api": {}, "some-api-2": {}}, nil)
+
+	assert.Equal(t, JobRunning, job.Status)
+	assert.Equal(t, TargetPending, job.Targets["some-api"].Status)
+
+	store.setTarget(job.ID, "some-api", TargetScaled, "VanillaHpa", "")
+	store.setTarget(job.ID, "some-api-2", TargetFailed, "", "not found")
+	store.finish(job.ID)
+
+	job, ok := store.Get(job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, JobFailed, job.Status)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Equal(t, "VanillaHpa", job.Targets["some-api"].Type)
+	assert.Equal(t, "not found", job.Targets["some-api-2"].Reason)
+}
+
+func TestJobStore_Prune(t *testing.T) {
+	store := NewJobStore()
+	first := store.create(ScaleConfigs{}, nil)
+	store.finish(first.ID)
+
+	for i := 1; i < maxJobs; i++ {
+		store.create(ScaleConfigs{}, nil)
+	}
+	store.create(ScaleConfigs{}, nil)
+
+	_, ok := store.Get(first.ID)
+	assert.False(t, ok)
+	assert.Len(t, store.List(), maxJobs)
+}
+
+func TestSubmitJob_TracksTargets(t *testing.T) {
+	facade := newTestFacade()
+
+	job, err := facade.SubmitJob(context.TODO(), ScaleConfigs{
+		deployMocks["NormalDeploy"].Name: {Min: 3, Max: 5},
+		"missing":                        {Min: 3, Max: 5},
+	}, UpdateOptions{})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, _ = facade.GetJob(job.ID)
+		return job.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, JobFailed, job.Status)
+	assert.Equal(t, TargetScaled, job.Targets["NormalDeploy"].Status)
+	assert.Equal(t, "VanillaHpa", job.Targets["NormalDeploy"].Type)
+	assert.Equal(t, TargetFailed, job.Targets["missing"].Status)
+	assert.NotEmpty(t, job.Targets["missing"].Reason)
+}
+
+func TestCancelJob_StopsPendingTargets(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["NormalDeploy"].Name
+	job, err := facade.SubmitJob(context.TODO(), ScaleConfigs{
+		"first":  {Namespace: name, Deployment: name, Min: 3, Max: 5},
+		"second": {Namespace: name, Deployment: name, Min: 3, Max: 5},
+	}, UpdateOptions{Sleep: time.Minute})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, _ = facade.GetJob(job.ID)
+		return len(job.Changed()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	job, ok := facade.CancelJob(context.TODO(), job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, JobCanceled, job.Status)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Len(t, job.Changed(), 1)
+
+	for name, target := range job.Targets {
+		if target.Status != TargetScaled {
+			assert.Equal(t, TargetCanceled, target.Status, name)
+		}
+	}
+
+	_, ok = facade.CancelJob(context.TODO(), "unknown")
+	assert.False(t, ok)
+}
+
+func TestJobStore_SetReady(t *testing.T) {
+	store := NewJobStore()
+	job := store.create(ScaleConfigs{"some-api": {}, "some-api-2": {}}, nil)
+
+	store.setScaled(job.ID, "some-api", "VanillaHpa", ScaleBounds{Min: 1, Max: 2}, ScaleBounds{Min: 3, Max: 5}, time.Second)
+	store.setScaled(job.ID, "some-api-2", "VanillaHpa", ScaleBounds{Min: 1, Max: 2}, ScaleBounds{Min: 3, Max: 5}, time.Second)
+	store.setReady(job.ID, "some-api", 2*time.Second, nil)
+	store.setReady(job.ID, "some-api-2", 0, fmt.Errorf("1 of 3 replicas available after 1m0s"))
+	store.finish(job.ID)
+
+	job, _ = store.Get(job.ID)
+	assert.Equal(t, JobFailed, job.Status)
+	assert.True(t, *job.Targets["some-api"].Ready)
+	assert.Equal(t, 2*time.Second, job.Targets["some-api"].TimeToReady.Duration)
+	assert.False(t, *job.Targets["some-api-2"].Ready)
+	assert.Nil(t, job.Targets["some-api-2"].TimeToReady)
+	assert.Contains(t, job.Targets["some-api-2"].Reason, "not ready")
+	assert.Equal(t, []string{"some-api", "some-api-2"}, job.Changed())
+}
+
+func TestWaitForAvailable(t *testing.T) {
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"},
+		Status:     v1.DeploymentStatus{AvailableReplicas: 1},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)
+	helper := &k8sHelper{clientset: fake.NewSimpleClientset(), dynamic: dynamicClient}
+
+	err := helper.waitForAvailableWithTimeout(context.TODO(), "shop", "Deployment", "checkout", 3, 200*time.Millisecond)
+	assert.EqualError(t, err, "1 of 3 replicas available after 200ms")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		updated := deploy.DeepCopy()
+		updated.Status.AvailableReplicas = 3
+		object, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(updated)
+		dynamicClient.Resource(targetKinds["Deployment"]).Namespace("shop").UpdateStatus(context.TODO(), &unstructured.Unstructured{Object: object}, metav1.UpdateOptions{})
+	}()
+
+	err = helper.waitForAvailableWithTimeout(context.TODO(), "shop", "Deployment", "checkout", 3, 5*time.Second)
+	assert.Nil(t, err)
+}
+
+func TestWaitJob_ReturnsResults(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["NormalDeploy"].Name
+	job, err := facade.SubmitJob(context.TODO(), ScaleConfigs{
+		name:      {Min: 4, Max: 8},
+		"missing": {Min: 3, Max: 5},
+	}, UpdateOptions{})
+	assert.Nil(t, err)
+
+	job, ok := facade.WaitJob(context.TODO(), job.ID)
+	assert.True(t, ok)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Equal(t, JobFailed, job.Status)
+
+	scaled := job.Targets[name]
+	assert.Equal(t, "VanillaHpa", scaled.Type)
+	assert.NotNil(t, scaled.Previous)
+	assert.Equal(t, ScaleBounds{Min: 4, Max: 8}, *scaled.Applied)
+	assert.Equal(t, TargetFailed, job.Targets["missing"].Status)
+	assert.NotEmpty(t, job.Targets["missing"].Reason)
+
+	_, ok = facade.WaitJob(context.TODO(), "unknown")
+	assert.False(t, ok)
+}
+
+func TestUpdateWithConcurrency_ReturnsResults(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["NormalDeploy"].Name
+	job, err := facade.UpdateWithConcurrency(context.TODO(), ScaleConfigs{
+		name:      {Min: 3, Max: 5},
+		"missing": {Min: 3, Max: 5},
+	}, nil)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing failed")
+	assert.NotContains(t, err.Error(), name)
+	assert.Len(t, job.Targets, 2)
+	assert.Equal(t, TargetScaled, job.Targets[name].Status)
+	assert.NotNil(t, job.Targets[name].ScaledIn)
+	assert.Equal(t, TargetFailed, job.Targets["missing"].Status)
+
+	job, err = facade.UpdateWithConcurrency(context.TODO(), ScaleConfigs{name: {Min: 3, Max: 5}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, JobSucceeded, job.Status)
+}
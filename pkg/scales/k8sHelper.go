@@ -2,98 +2,588 @@ package scales
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"time"
 
-	v1 "k8s.io/api/apps/v1"
-	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/retry"
 )
 
 //go:generate mockgen --destination=./k8shelper_mock.go -source=./k8sHelper.go -package=scales -self_package=github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales
 type k8sHelperInterface interface {
-	getDeploymentWithTimeout(deployName string, timeout time.Duration) (*v1.Deployment, error)
-	getHpaWithTimeout(name string, timeout time.Duration) (*autoscalingv1.HorizontalPodAutoscaler, error)
-	updateHpaWithTimeout(name string, hpaConfig *autoscalingv1.HorizontalPodAutoscaler, timeout time.Duration) error
-	updateDeployWithTimeout(name string, deployConfig *v1.Deployment, timeout time.Duration) error
+	getTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*unstructured.Unstructured, error)
+	getHpaWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*autoscalingv2.HorizontalPodAutoscaler, error)
+	getHpaForTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*autoscalingv2.HorizontalPodAutoscaler, error)
+	listHpasWithTimeout(ctx context.Context, namespace, selector string, timeout time.Duration) ([]autoscalingv2.HorizontalPodAutoscaler, error)
+	listTargetsWithTimeout(ctx context.Context, namespace, kind, selector string, timeout time.Duration) ([]unstructured.Unstructured, error)
+	listNamespacesWithTimeout(ctx context.Context, selector string, timeout time.Duration) ([]string, error)
+	listResourceQuotasWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]apiv1.ResourceQuota, error)
+	listNodesWithTimeout(ctx context.Context, timeout time.Duration) ([]apiv1.Node, error)
+	listPodsWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]apiv1.Pod, error)
+	patchHpaWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error
+	annotateTargetWithTimeout(ctx context.Context, namespace, kind, name string, annotations map[string]string, timeout time.Duration) error
+	getReplicasWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (int32, error)
+	setReplicasWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error
+	waitForAvailableWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error
+	getScaledObjectWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*unstructured.Unstructured, error)
+	patchScaledObjectWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error
+	withDryRun() k8sHelperInterface
+	withPolicy(policy Policy) k8sHelperInterface
+	withRateLimiter(limiter flowcontrol.RateLimiter) k8sHelperInterface
+	withHpaCache() k8sHelperInterface
 }
 
 type k8sHelper struct {
 	clientset kubernetes.Interface
-	ctx       context.Context
+	dynamic   dynamic.Interface
+	discovery *discoveryCache
+	hpas      *hpaCache
+	dryRun    bool
+	policy    Policy
+	limiter   flowcontrol.RateLimiter
 }
 
-func newK8sHelper() *k8sHelper {
-	config, err := rest.InClusterConfig()
-
+func newK8sHelper(config *rest.Config) (*k8sHelper, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		panic("Not able to connect with kubernetes cluster")
+		return nil, fmt.Errorf("not able to connect with kubernetes cluster: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("not able to connect with kubernetes cluster: %w", err)
 	}
 
 	client := kubernetes.Interface(clientset)
 
 	return &k8sHelper{
 		clientset: client,
-		ctx:       context.Background(),
+		dynamic:   dynamicClient,
+		discovery: &discoveryCache{},
+	}, nil
+}
+
+// Discovery results shared by a helper and the helpers derived from it.
+// A nil cache discovers on every call.
+type discoveryCache struct {
+	hpaV2Once sync.Once
+	hpaV2     bool
+	kindsMu   sync.Mutex
+	kinds     map[string]schema.GroupVersionResource
+}
+
+// Returns the resource of a target kind, discovering kinds that are not well known
+func (k *k8sHelper) targetResource(kind string) (schema.GroupVersionResource, error) {
+	if gvr, ok := targetKinds[kind]; ok {
+		return gvr, nil
+	}
+	return k.discovery.targetResource(k.clientset.Discovery(), kind)
+}
+
+func (d *discoveryCache) targetResource(client discovery.DiscoveryInterface, kind string) (schema.GroupVersionResource, error) {
+	if d == nil {
+		return discoverTargetKind(client, kind)
+	}
+
+	d.kindsMu.Lock()
+	defer d.kindsMu.Unlock()
+
+	if gvr, ok := d.kinds[kind]; ok {
+		return gvr, nil
 	}
+
+	gvr, err := discoverTargetKind(client, kind)
+	if err != nil {
+		return gvr, err
+	}
+
+	if d.kinds == nil {
+		d.kinds = make(map[string]schema.GroupVersionResource)
+	}
+	d.kinds[kind] = gvr
+	return gvr, nil
 }
 
-func (k *k8sHelper) getDeploymentWithTimeout(deployName string, timeout time.Duration) (*v1.Deployment, error) {
-	ctx, cancel := context.WithTimeout(k.ctx, timeout)
+// Gets a scalable workload of any kind through the dynamic client
+func (k *k8sHelper) getTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	deploy, err := k.clientset.AppsV1().Deployments(deployName).Get(ctx, deployName, metav1.GetOptions{})
+	target, err := k.dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 
-	if k.accessOrNotFoundError(err) {
+	if err != nil {
 		return nil, err
 	}
 
-	return deploy, nil
+	return target, nil
 }
 
-func (k *k8sHelper) getHpaWithTimeout(name string, timeout time.Duration) (*autoscalingv1.HorizontalPodAutoscaler, error) {
-	ctx, cancel := context.WithTimeout(k.ctx, timeout*time.Millisecond)
+// Whether the server offers autoscaling/v2, detected on first use
+func (k *k8sHelper) supportsHpaV2() bool {
+	return k.discovery.supportsHpaV2(k.clientset.Discovery())
+}
+
+func (d *discoveryCache) supportsHpaV2(client discovery.DiscoveryInterface) bool {
+	if d == nil {
+		return servesHpaV2(client)
+	}
+
+	d.hpaV2Once.Do(func() {
+		d.hpaV2 = servesHpaV2(client)
+	})
+	return d.hpaV2
+}
+
+func servesHpaV2(client discovery.DiscoveryInterface) bool {
+	resources, err := client.ServerResourcesForGroupVersion(autoscalingv2.SchemeGroupVersion.String())
+	if err != nil {
+		return false
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == "horizontalpodautoscalers" {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the HPA as autoscaling/v2, converting from autoscaling/v1 when v2 is not served
+func (k *k8sHelper) getHpaWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	hpa, err := k.clientset.AutoscalingV1().HorizontalPodAutoscalers(name).Get(ctx, name, metav1.GetOptions{})
-	if k.accessOrNotFoundError(err) {
+
+	if k.supportsHpaV2() {
+		hpa, err := k.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return hpa, nil
+	}
+
+	hpa, err := k.clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
 		return nil, err
 	}
 
-	return hpa, nil
+	return hpaV1ToV2(hpa), nil
 }
 
-func (k *k8sHelper) executeUpdateWithTimeout(f func(client kubernetes.Interface, ctx context.Context) error, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(k.ctx, timeout*time.Millisecond)
+// Returns the HPA whose scaleTargetRef points to the workload
+func (k *k8sHelper) getHpaForTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas, err := k.namespaceHpasWithTimeout(ctx, namespace, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hpas {
+		ref := hpas[i].Spec.ScaleTargetRef
+		if ref.Kind == kind && ref.Name == name {
+			return &hpas[i], nil
+		}
+	}
+
+	return nil, errors.NewNotFound(autoscalingv2.Resource("horizontalpodautoscalers"), fmt.Sprintf("targeting %s %s/%s", kind, namespace, name))
+}
+
+// HPAs of a namespace, listed once per namespace when the helper caches them
+type hpaCache struct {
+	mu    sync.Mutex
+	lists map[string]*hpaList
+}
+
+type hpaList struct {
+	once sync.Once
+	hpas []autoscalingv2.HorizontalPodAutoscaler
+	err  error
+}
+
+// Lists every HPA of the namespace, or reuses the list of an earlier call on a caching helper
+func (k *k8sHelper) namespaceHpasWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	if k.hpas == nil {
+		return k.listHpasWithTimeout(ctx, namespace, "", timeout)
+	}
+
+	k.hpas.mu.Lock()
+	list, ok := k.hpas.lists[namespace]
+	if !ok {
+		list = &hpaList{}
+		k.hpas.lists[namespace] = list
+	}
+	k.hpas.mu.Unlock()
+
+	list.once.Do(func() {
+		list.hpas, list.err = k.listHpasWithTimeout(ctx, namespace, "", timeout)
+	})
+	return list.hpas, list.err
+}
+
+// Lists the HPAs matching the label selector as autoscaling/v2, every namespace when namespace is empty
+func (k *k8sHelper) listHpasWithTimeout(ctx context.Context, namespace, selector string, timeout time.Duration) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	options := metav1.ListOptions{LabelSelector: selector}
+	if k.supportsHpaV2() {
+		list, err := k.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	list, err := k.clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	hpas := make([]autoscalingv2.HorizontalPodAutoscaler, 0, len(list.Items))
+	for i := range list.Items {
+		hpas = append(hpas, *hpaV1ToV2(&list.Items[i]))
+	}
+	return hpas, nil
+}
+
+// Lists the workloads of a kind matching the label selector, every namespace when namespace is empty
+func (k *k8sHelper) listTargetsWithTimeout(ctx context.Context, namespace, kind, selector string, timeout time.Duration) ([]unstructured.Unstructured, error) {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	return f(k.clientset, ctx)
+	list, err := k.dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
 }
 
-func (k *k8sHelper) updateHpaWithTimeout(name string, hpaConfig *autoscalingv1.HorizontalPodAutoscaler, timeout time.Duration) error {
-	return k.executeUpdateWithTimeout(func(client kubernetes.Interface, ctx context.Context) error {
-		_, err := client.AutoscalingV1().HorizontalPodAutoscalers(name).Update(ctx, hpaConfig, metav1.UpdateOptions{})
-		if k.accessOrNotFoundError(err) {
+// Returns the names of the namespaces matching the label selector
+func (k *k8sHelper) listNamespacesWithTimeout(ctx context.Context, selector string, timeout time.Duration) ([]string, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	list, err := k.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
+func (k *k8sHelper) listResourceQuotasWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]apiv1.ResourceQuota, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	list, err := k.clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+func (k *k8sHelper) listNodesWithTimeout(ctx context.Context, timeout time.Duration) ([]apiv1.Node, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	list, err := k.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// Lists the pods of the namespace, every namespace when namespace is empty
+func (k *k8sHelper) listPodsWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]apiv1.Pod, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	list, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// Runs an update, retrying with backoff while the API server reports
+// a conflict or is temporarily unable to serve it
+func (k *k8sHelper) executeUpdateWithTimeout(ctx context.Context, f func(client kubernetes.Interface, ctx context.Context) error, timeout time.Duration) error {
+	attempts := 0
+	backoff := DefaultPolicy().withOverride(k.policy).backoff()
+	err := retry.OnError(backoff, retriableError, func() error {
+		attempts++
+		if err := k.throttle(ctx); err != nil {
 			return err
 		}
-		return nil
-	}, timeout)
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return f(k.clientset, ctx)
+	})
+
+	if err != nil && attempts > 1 {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return err
 }
 
-func (k *k8sHelper) updateDeployWithTimeout(name string, deployConfig *v1.Deployment, timeout time.Duration) error {
-	return k.executeUpdateWithTimeout(func(client kubernetes.Interface, ctx context.Context) error {
-		_, err := client.AppsV1().Deployments(name).Update(ctx, deployConfig, metav1.UpdateOptions{})
-		if k.accessOrNotFoundError(err) {
+// Sets min and max with a merge patch, the same patch applies to autoscaling/v1 and v2
+func (k *k8sHelper) patchHpaWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"minReplicas":%d,"maxReplicas":%d}}`, min, max))
+	return k.executeUpdateWithTimeout(ctx, func(client kubernetes.Interface, ctx context.Context) error {
+		if k.supportsHpaV2() {
+			_, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Patch(ctx, name, types.MergePatchType, patch, k.patchOptions())
 			return err
 		}
-		return nil
+
+		_, err := client.AutoscalingV1().HorizontalPodAutoscalers(namespace).Patch(ctx, name, types.MergePatchType, patch, k.patchOptions())
+		return err
+	}, timeout)
+}
+
+// Sets the given annotations with a merge patch, leaving the others untouched
+func (k *k8sHelper) annotateTargetWithTimeout(ctx context.Context, namespace, kind, name string, annotations map[string]string, timeout time.Duration) error {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+
+	return k.executeUpdateWithTimeout(ctx, func(client kubernetes.Interface, ctx context.Context) error {
+		_, err := k.dynamic.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, k.patchOptions())
+		return err
+	}, timeout)
+}
+
+// Reads spec.replicas through the scale subresource
+func (k *k8sHelper) getReplicasWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (int32, error) {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := k.throttle(ctx); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	scale, err := k.dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return 0, err
+	}
+
+	replicas, _, err := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	return int32(replicas), err
+}
+
+// Sets spec.replicas through the scale subresource
+func (k *k8sHelper) setReplicasWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	return k.executeUpdateWithTimeout(ctx, func(client kubernetes.Interface, ctx context.Context) error {
+		_, err := k.dynamic.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, k.patchOptions(), "scale")
+		return err
+	}, timeout)
+}
+
+// Watches a workload until its status reports at least replicas available
+func (k *k8sHelper) waitForAvailableWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error {
+	gvr, err := k.targetResource(kind)
+	if err != nil {
+		return err
+	}
+
+	if err := k.throttle(ctx); err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := k.dynamic.Resource(gvr).Namespace(namespace)
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.List(waitCtx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.Watch(waitCtx, options)
+		},
+	}
+
+	var available int64
+	_, err = watchtools.UntilWithSync(waitCtx, lw, &unstructured.Unstructured{}, nil, func(event watch.Event) (bool, error) {
+		target, ok := event.Object.(*unstructured.Unstructured)
+		if !ok || target.GetName() != name {
+			return false, nil
+		}
+
+		if event.Type == watch.Deleted {
+			return false, errors.NewNotFound(gvr.GroupResource(), name)
+		}
+
+		available, _, err = unstructured.NestedInt64(target.Object, "status", "availableReplicas")
+		return available >= int64(replicas), err
+	})
+
+	// Canceling ctx is reported as is, only the timeout is explained
+	if err != nil && ctx.Err() == nil && waitCtx.Err() != nil {
+		return fmt.Errorf("%d of %d replicas available after %s", available, replicas, timeout)
+	}
+	return err
+}
+
+func (k *k8sHelper) getScaledObjectWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	if err := k.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	scaledObject, err := k.dynamic.Resource(scaledObjectResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return scaledObject, nil
+}
+
+// Sets minReplicaCount and maxReplicaCount with a merge patch
+func (k *k8sHelper) patchScaledObjectWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"minReplicaCount":%d,"maxReplicaCount":%d}}`, min, max))
+	return k.executeUpdateWithTimeout(ctx, func(client kubernetes.Interface, ctx context.Context) error {
+		_, err := k.dynamic.Resource(scaledObjectResource).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, k.patchOptions())
+		return err
 	}, timeout)
 }
 
-func (k *k8sHelper) accessError(err error) bool {
-	return errors.IsForbidden(err) || errors.IsUnauthorized(err)
+// Returns a helper sending every update as a server-side dry run
+func (k *k8sHelper) withDryRun() k8sHelperInterface {
+	helper := *k
+	helper.dryRun = true
+	return &helper
+}
+
+// Returns a helper retrying its updates as the policy says
+func (k *k8sHelper) withPolicy(policy Policy) k8sHelperInterface {
+	helper := *k
+	helper.policy = policy
+	return &helper
+}
+
+// Returns a helper waiting on limiter before every request it sends
+func (k *k8sHelper) withRateLimiter(limiter flowcontrol.RateLimiter) k8sHelperInterface {
+	helper := *k
+	helper.limiter = limiter
+	return &helper
+}
+
+// Returns a helper listing the HPAs of each namespace once, for the targets of a single job
+func (k *k8sHelper) withHpaCache() k8sHelperInterface {
+	helper := *k
+	helper.hpas = &hpaCache{lists: make(map[string]*hpaList)}
+	return &helper
+}
+
+// Blocks until the rate limiter of the helper, if any, allows another request
+func (k *k8sHelper) throttle(ctx context.Context) error {
+	if k.limiter == nil {
+		return nil
+	}
+	return k.limiter.Wait(ctx)
+}
+
+func (k *k8sHelper) dryRunOption() []string {
+	if k.dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+func (k *k8sHelper) patchOptions() metav1.PatchOptions {
+	return metav1.PatchOptions{DryRun: k.dryRunOption()}
 }
 
-func (k *k8sHelper) accessOrNotFoundError(err error) bool {
-	return k.accessError(err) || errors.IsNotFound(err)
+// Errors worth another attempt, anything else is returned right away
+func retriableError(err error) bool {
+	return errors.IsConflict(err) || errors.IsServerTimeout(err) || errors.IsTimeout(err) ||
+		errors.IsTooManyRequests(err) || errors.IsServiceUnavailable(err)
 }
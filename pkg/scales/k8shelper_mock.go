@@ -5,12 +5,15 @@
 package scales
 
 import (
+	context "context"
 	reflect "reflect"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	v1 "k8s.io/api/apps/v1"
-	v10 "k8s.io/api/autoscaling/v1"
+	v2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	flowcontrol "k8s.io/client-go/util/flowcontrol"
 )
 
 // Mockk8sHelperInterface is a mock of k8sHelperInterface interface.
@@ -36,60 +39,293 @@ func (m *Mockk8sHelperInterface) EXPECT() *Mockk8sHelperInterfaceMockRecorder {
 	return m.recorder
 }
 
-// getDeploymentWithTimeout mocks base method.
-func (m *Mockk8sHelperInterface) getDeploymentWithTimeout(deployName string, timeout time.Duration) (*v1.Deployment, error) {
+// annotateTargetWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) annotateTargetWithTimeout(ctx context.Context, namespace, kind, name string, annotations map[string]string, timeout time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "getDeploymentWithTimeout", deployName, timeout)
-	ret0, _ := ret[0].(*v1.Deployment)
+	ret := m.ctrl.Call(m, "annotateTargetWithTimeout", ctx, namespace, kind, name, annotations, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// annotateTargetWithTimeout indicates an expected call of annotateTargetWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) annotateTargetWithTimeout(ctx, namespace, kind, name, annotations, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "annotateTargetWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).annotateTargetWithTimeout), ctx, namespace, kind, name, annotations, timeout)
+}
+
+// getHpaForTargetWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) getHpaForTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*v2.HorizontalPodAutoscaler, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "getHpaForTargetWithTimeout", ctx, namespace, kind, name, timeout)
+	ret0, _ := ret[0].(*v2.HorizontalPodAutoscaler)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// getDeploymentWithTimeout indicates an expected call of getDeploymentWithTimeout.
-func (mr *Mockk8sHelperInterfaceMockRecorder) getDeploymentWithTimeout(deployName, timeout interface{}) *gomock.Call {
+// getHpaForTargetWithTimeout indicates an expected call of getHpaForTargetWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) getHpaForTargetWithTimeout(ctx, namespace, kind, name, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getDeploymentWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getDeploymentWithTimeout), deployName, timeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getHpaForTargetWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getHpaForTargetWithTimeout), ctx, namespace, kind, name, timeout)
 }
 
 // getHpaWithTimeout mocks base method.
-func (m *Mockk8sHelperInterface) getHpaWithTimeout(name string, timeout time.Duration) (*v10.HorizontalPodAutoscaler, error) {
+func (m *Mockk8sHelperInterface) getHpaWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*v2.HorizontalPodAutoscaler, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "getHpaWithTimeout", name, timeout)
-	ret0, _ := ret[0].(*v10.HorizontalPodAutoscaler)
+	ret := m.ctrl.Call(m, "getHpaWithTimeout", ctx, namespace, name, timeout)
+	ret0, _ := ret[0].(*v2.HorizontalPodAutoscaler)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // getHpaWithTimeout indicates an expected call of getHpaWithTimeout.
-func (mr *Mockk8sHelperInterfaceMockRecorder) getHpaWithTimeout(name, timeout interface{}) *gomock.Call {
+func (mr *Mockk8sHelperInterfaceMockRecorder) getHpaWithTimeout(ctx, namespace, name, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getHpaWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getHpaWithTimeout), ctx, namespace, name, timeout)
+}
+
+// getReplicasWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) getReplicasWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "getReplicasWithTimeout", ctx, namespace, kind, name, timeout)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// getReplicasWithTimeout indicates an expected call of getReplicasWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) getReplicasWithTimeout(ctx, namespace, kind, name, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getReplicasWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getReplicasWithTimeout), ctx, namespace, kind, name, timeout)
+}
+
+// getScaledObjectWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) getScaledObjectWithTimeout(ctx context.Context, namespace, name string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "getScaledObjectWithTimeout", ctx, namespace, name, timeout)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// getScaledObjectWithTimeout indicates an expected call of getScaledObjectWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) getScaledObjectWithTimeout(ctx, namespace, name, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getScaledObjectWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getScaledObjectWithTimeout), ctx, namespace, name, timeout)
+}
+
+// getTargetWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) getTargetWithTimeout(ctx context.Context, namespace, kind, name string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "getTargetWithTimeout", ctx, namespace, kind, name, timeout)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// getTargetWithTimeout indicates an expected call of getTargetWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) getTargetWithTimeout(ctx, namespace, kind, name, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getTargetWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getTargetWithTimeout), ctx, namespace, kind, name, timeout)
+}
+
+// listHpasWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listHpasWithTimeout(ctx context.Context, namespace, selector string, timeout time.Duration) ([]v2.HorizontalPodAutoscaler, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listHpasWithTimeout", ctx, namespace, selector, timeout)
+	ret0, _ := ret[0].([]v2.HorizontalPodAutoscaler)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listHpasWithTimeout indicates an expected call of listHpasWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listHpasWithTimeout(ctx, namespace, selector, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listHpasWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listHpasWithTimeout), ctx, namespace, selector, timeout)
+}
+
+// listNamespacesWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listNamespacesWithTimeout(ctx context.Context, selector string, timeout time.Duration) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listNamespacesWithTimeout", ctx, selector, timeout)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listNamespacesWithTimeout indicates an expected call of listNamespacesWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listNamespacesWithTimeout(ctx, selector, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listNamespacesWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listNamespacesWithTimeout), ctx, selector, timeout)
+}
+
+// listNodesWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listNodesWithTimeout(ctx context.Context, timeout time.Duration) ([]v1.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listNodesWithTimeout", ctx, timeout)
+	ret0, _ := ret[0].([]v1.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listNodesWithTimeout indicates an expected call of listNodesWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listNodesWithTimeout(ctx, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listNodesWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listNodesWithTimeout), ctx, timeout)
+}
+
+// listPodsWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listPodsWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]v1.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listPodsWithTimeout", ctx, namespace, timeout)
+	ret0, _ := ret[0].([]v1.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listPodsWithTimeout indicates an expected call of listPodsWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listPodsWithTimeout(ctx, namespace, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listPodsWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listPodsWithTimeout), ctx, namespace, timeout)
+}
+
+// listResourceQuotasWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listResourceQuotasWithTimeout(ctx context.Context, namespace string, timeout time.Duration) ([]v1.ResourceQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listResourceQuotasWithTimeout", ctx, namespace, timeout)
+	ret0, _ := ret[0].([]v1.ResourceQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listResourceQuotasWithTimeout indicates an expected call of listResourceQuotasWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listResourceQuotasWithTimeout(ctx, namespace, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listResourceQuotasWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listResourceQuotasWithTimeout), ctx, namespace, timeout)
+}
+
+// listTargetsWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) listTargetsWithTimeout(ctx context.Context, namespace, kind, selector string, timeout time.Duration) ([]unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "listTargetsWithTimeout", ctx, namespace, kind, selector, timeout)
+	ret0, _ := ret[0].([]unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// listTargetsWithTimeout indicates an expected call of listTargetsWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) listTargetsWithTimeout(ctx, namespace, kind, selector, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "listTargetsWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).listTargetsWithTimeout), ctx, namespace, kind, selector, timeout)
+}
+
+// patchHpaWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) patchHpaWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "patchHpaWithTimeout", ctx, namespace, name, min, max, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// patchHpaWithTimeout indicates an expected call of patchHpaWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) patchHpaWithTimeout(ctx, namespace, name, min, max, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "patchHpaWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).patchHpaWithTimeout), ctx, namespace, name, min, max, timeout)
+}
+
+// patchScaledObjectWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) patchScaledObjectWithTimeout(ctx context.Context, namespace, name string, min, max int32, timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "patchScaledObjectWithTimeout", ctx, namespace, name, min, max, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// patchScaledObjectWithTimeout indicates an expected call of patchScaledObjectWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) patchScaledObjectWithTimeout(ctx, namespace, name, min, max, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getHpaWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).getHpaWithTimeout), name, timeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "patchScaledObjectWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).patchScaledObjectWithTimeout), ctx, namespace, name, min, max, timeout)
 }
 
-// updateDeployWithTimeout mocks base method.
-func (m *Mockk8sHelperInterface) updateDeployWithTimeout(name string, deployConfig *v1.Deployment, timeout time.Duration) error {
+// setReplicasWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) setReplicasWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "updateDeployWithTimeout", name, deployConfig, timeout)
+	ret := m.ctrl.Call(m, "setReplicasWithTimeout", ctx, namespace, kind, name, replicas, timeout)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// updateDeployWithTimeout indicates an expected call of updateDeployWithTimeout.
-func (mr *Mockk8sHelperInterfaceMockRecorder) updateDeployWithTimeout(name, deployConfig, timeout interface{}) *gomock.Call {
+// setReplicasWithTimeout indicates an expected call of setReplicasWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) setReplicasWithTimeout(ctx, namespace, kind, name, replicas, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "updateDeployWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).updateDeployWithTimeout), name, deployConfig, timeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "setReplicasWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).setReplicasWithTimeout), ctx, namespace, kind, name, replicas, timeout)
 }
 
-// updateHpaWithTimeout mocks base method.
-func (m *Mockk8sHelperInterface) updateHpaWithTimeout(name string, hpaConfig *v10.HorizontalPodAutoscaler, timeout time.Duration) error {
+// waitForAvailableWithTimeout mocks base method.
+func (m *Mockk8sHelperInterface) waitForAvailableWithTimeout(ctx context.Context, namespace, kind, name string, replicas int32, timeout time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "updateHpaWithTimeout", name, hpaConfig, timeout)
+	ret := m.ctrl.Call(m, "waitForAvailableWithTimeout", ctx, namespace, kind, name, replicas, timeout)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// updateHpaWithTimeout indicates an expected call of updateHpaWithTimeout.
-func (mr *Mockk8sHelperInterfaceMockRecorder) updateHpaWithTimeout(name, hpaConfig, timeout interface{}) *gomock.Call {
+// waitForAvailableWithTimeout indicates an expected call of waitForAvailableWithTimeout.
+func (mr *Mockk8sHelperInterfaceMockRecorder) waitForAvailableWithTimeout(ctx, namespace, kind, name, replicas, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "waitForAvailableWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).waitForAvailableWithTimeout), ctx, namespace, kind, name, replicas, timeout)
+}
+
+// withDryRun mocks base method.
+func (m *Mockk8sHelperInterface) withDryRun() k8sHelperInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withDryRun")
+	ret0, _ := ret[0].(k8sHelperInterface)
+	return ret0
+}
+
+// withDryRun indicates an expected call of withDryRun.
+func (mr *Mockk8sHelperInterfaceMockRecorder) withDryRun() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withDryRun", reflect.TypeOf((*Mockk8sHelperInterface)(nil).withDryRun))
+}
+
+// withHpaCache mocks base method.
+func (m *Mockk8sHelperInterface) withHpaCache() k8sHelperInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withHpaCache")
+	ret0, _ := ret[0].(k8sHelperInterface)
+	return ret0
+}
+
+// withHpaCache indicates an expected call of withHpaCache.
+func (mr *Mockk8sHelperInterfaceMockRecorder) withHpaCache() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withHpaCache", reflect.TypeOf((*Mockk8sHelperInterface)(nil).withHpaCache))
+}
+
+// withPolicy mocks base method.
+func (m *Mockk8sHelperInterface) withPolicy(policy Policy) k8sHelperInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withPolicy", policy)
+	ret0, _ := ret[0].(k8sHelperInterface)
+	return ret0
+}
+
+// withPolicy indicates an expected call of withPolicy.
+func (mr *Mockk8sHelperInterfaceMockRecorder) withPolicy(policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withPolicy", reflect.TypeOf((*Mockk8sHelperInterface)(nil).withPolicy), policy)
+}
+
+// withRateLimiter mocks base method.
+func (m *Mockk8sHelperInterface) withRateLimiter(limiter flowcontrol.RateLimiter) k8sHelperInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "withRateLimiter", limiter)
+	ret0, _ := ret[0].(k8sHelperInterface)
+	return ret0
+}
+
+// withRateLimiter indicates an expected call of withRateLimiter.
+func (mr *Mockk8sHelperInterfaceMockRecorder) withRateLimiter(limiter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "updateHpaWithTimeout", reflect.TypeOf((*Mockk8sHelperInterface)(nil).updateHpaWithTimeout), name, hpaConfig, timeout)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "withRateLimiter", reflect.TypeOf((*Mockk8sHelperInterface)(nil).withRateLimiter), limiter)
 }
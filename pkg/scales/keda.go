@@ -0,0 +1,115 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Label KEDA sets on the HPAs it owns, holding the ScaledObject name
+const kedaScaledObjectLabel = "scaledobject.keda.sh/name"
+
+// KEDA defaults when the ScaledObject does not set replica counts
+const (
+	kedaDefaultMinReplicas = 0
+	kedaDefaultMaxReplicas = 100
+)
+
+var scaledObjectResource = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}
+
+// Implements Scaler Interface
+type kedaScaler struct {
+	logger    *logrus.Logger
+	timeout   time.Duration
+	k8sHelper k8sHelperInterface
+}
+
+func newKedaScaler(k8sHelper k8sHelperInterface, logger *logrus.Logger, timeout time.Duration) *kedaScaler {
+	return &kedaScaler{
+		logger:    logger,
+		timeout:   timeout,
+		k8sHelper: k8sHelper,
+	}
+}
+
+// Returns the name of the ScaledObject owning the HPA, if any
+func kedaScaledObjectName(hpa *autoscalingv2.HorizontalPodAutoscaler) (string, bool) {
+	name, ok := hpa.Labels[kedaScaledObjectLabel]
+	return name, ok && name != ""
+}
+
+// Patches only minReplicaCount and maxReplicaCount, KEDA carries them to its HPA
+func (k *kedaScaler) Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	scaledObject, err := k.getScaledObject(ctx, config)
+
+	if err != nil {
+		return config, err
+	}
+
+	min, max, err := scaledObjectBounds(scaledObject)
+	if err != nil {
+		return config, err
+	}
+
+	if config, err = config.resolve(min, max); err != nil {
+		return config, err
+	}
+
+	return config, k.k8sHelper.patchScaledObjectWithTimeout(ctx, config.targetNamespace(), scaledObject.GetName(), int32(config.Min), int32(config.Max), k.timeout)
+}
+
+// Returns config with min and max read from the ScaledObject
+func (k *kedaScaler) CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	scaledObject, err := k.getScaledObject(ctx, config)
+
+	if err != nil {
+		return config, err
+	}
+
+	config.Min, config.Max, err = scaledObjectBounds(scaledObject)
+	return config, err
+}
+
+// Min and max of the ScaledObject, KEDA defaults applied when not set
+func scaledObjectBounds(scaledObject *unstructured.Unstructured) (int, int, error) {
+	min, found, err := unstructured.NestedInt64(scaledObject.Object, "spec", "minReplicaCount")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		min = kedaDefaultMinReplicas
+	}
+
+	max, found, err := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		max = kedaDefaultMaxReplicas
+	}
+
+	return int(min), int(max), nil
+}
+
+// Gets the ScaledObject by name, or through the HPA KEDA created for the workload
+func (k *kedaScaler) getScaledObject(ctx context.Context, config ScaleConfig) (*unstructured.Unstructured, error) {
+	name := config.ScaledObject
+	if name == "" {
+		hpa, err := k.k8sHelper.getHpaForTargetWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), k.timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if name, ok = kedaScaledObjectName(hpa); !ok {
+			return nil, fmt.Errorf("HPA %s of %s is not managed by KEDA", hpa.Name, config.Name)
+		}
+	}
+
+	return k.k8sHelper.getScaledObjectWithTimeout(ctx, config.targetNamespace(), name, k.timeout)
+}
@@ -0,0 +1,110 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newKedaFixture(spec map[string]interface{}) (*k8sHelper, *dynamicfake.FakeDynamicClient) {
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "queue"},
+	}
+	scaledObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata":   map[string]interface{}{"name": "worker-scaler", "namespace": "queue"},
+		"spec":       spec,
+	}}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "keda-hpa-worker-scaler",
+			Namespace: "queue",
+			Labels:    map[string]string{kedaScaledObjectLabel: "worker-scaler"},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "worker", APIVersion: "apps/v1"},
+			MaxReplicas:    20,
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy, scaledObject)
+	helper := &k8sHelper{
+		clientset: fake.NewSimpleClientset(hpa),
+		dynamic:   dynamicClient,
+	}
+	return helper, dynamicClient
+}
+
+func TestIdentifyHpaType_Keda(t *testing.T) {
+	helper, _ := newKedaFixture(map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{"name": "worker"},
+	})
+
+	config := ScaleConfig{Name: "worker", Namespace: "queue", Min: 5, Max: 30}
+	err := newScaleTypeHelper(helper, &fakeLogger, 500*time.Millisecond).IdentifyHpaType(context.TODO(), &config)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Keda", config.Type)
+	assert.Equal(t, "worker-scaler", config.ScaledObject)
+}
+
+func TestIdentifyHpaType_ExplicitKedaHpa(t *testing.T) {
+	helper, _ := newKedaFixture(map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{"name": "worker"},
+	})
+
+	config := ScaleConfig{Name: "worker", Namespace: "queue", Hpa: "keda-hpa-worker-scaler", Min: 5, Max: 30}
+	err := newScaleTypeHelper(helper, &fakeLogger, 500*time.Millisecond).IdentifyHpaType(context.TODO(), &config)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Keda", config.Type)
+	assert.Equal(t, "worker-scaler", config.ScaledObject)
+}
+
+func TestKedaScaler_Scale(t *testing.T) {
+	helper, dynamicClient := newKedaFixture(map[string]interface{}{
+		"scaleTargetRef":  map[string]interface{}{"name": "worker"},
+		"minReplicaCount": int64(1),
+		"maxReplicaCount": int64(20),
+	})
+	scaler := newKedaScaler(helper, &fakeLogger, 500*time.Millisecond)
+	config := ScaleConfig{Name: "worker", Namespace: "queue", Min: 5, Max: 30, Type: "Keda"}
+
+	current, err := scaler.CurrentConfig(context.TODO(), config)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, current.Min)
+	assert.Equal(t, 20, current.Max)
+
+	_, err = scaler.Scale(context.TODO(), config)
+	assert.Nil(t, err)
+
+	scaledObject, err := dynamicClient.Resource(scaledObjectResource).Namespace("queue").Get(context.TODO(), "worker-scaler", metav1.GetOptions{})
+	assert.Nil(t, err)
+	min, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "minReplicaCount")
+	max, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount")
+	assert.Equal(t, int64(5), min)
+	assert.Equal(t, int64(30), max)
+}
+
+func TestKedaScaler_CurrentConfigDefaults(t *testing.T) {
+	helper, _ := newKedaFixture(map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{"name": "worker"},
+	})
+
+	current, err := newKedaScaler(helper, &fakeLogger, 500*time.Millisecond).CurrentConfig(context.TODO(), ScaleConfig{Name: "worker", Namespace: "queue", ScaledObject: "worker-scaler"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, kedaDefaultMinReplicas, current.Min)
+	assert.Equal(t, kedaDefaultMaxReplicas, current.Max)
+}
@@ -1,37 +1,72 @@
 package scales
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	hpaOpMaxAnnotation = "hpa.autoscaling.banzaicloud.io/maxReplicas"
+	hpaOpMinAnnotation = "hpa.autoscaling.banzaicloud.io/minReplicas"
+)
+
 // Implements Scaler Interface
 type hpaOperator struct {
 	scaleConfigs ScaleConfigs
 	logger       *logrus.Logger
+	timeout      time.Duration
 	k8sHelper    k8sHelperInterface
 }
 
-func newHpaOperator(k8sHelper k8sHelperInterface, logger *logrus.Logger) *hpaOperator {
+func newHpaOperator(k8sHelper k8sHelperInterface, logger *logrus.Logger, timeout time.Duration) *hpaOperator {
 	return &hpaOperator{
 		logger:    logger,
+		timeout:   timeout,
 		k8sHelper: k8sHelper,
 	}
 }
 
-func (op *hpaOperator) Scale(config ScaleConfig) error {
-	deploy, err := op.k8sHelper.getDeploymentWithTimeout(config.Name, 500*time.Millisecond)
+// Patches only the two annotations, so concurrent writes to the workload are kept
+func (op *hpaOperator) Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	if config.relative() {
+		current, err := op.CurrentConfig(ctx, config)
+		if err != nil {
+			return config, err
+		}
+
+		if config, err = config.resolveCurrent(current); err != nil {
+			return config, err
+		}
+	}
+
+	annotations := map[string]string{
+		hpaOpMaxAnnotation: strconv.Itoa(config.Max),
+		hpaOpMinAnnotation: strconv.Itoa(config.Min),
+	}
+
+	return config, op.k8sHelper.annotateTargetWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), annotations, op.timeout)
+}
+
+// Returns config with min and max read from the workload annotations
+func (op *hpaOperator) CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	target, err := op.k8sHelper.getTargetWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), op.timeout)
 
 	if err != nil {
-		return err
+		return config, err
 	}
 
-	deploy.Annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"] = strconv.Itoa(config.Max)
-	deploy.Annotations["hpa.autoscaling.banzaicloud.io/minReplicas"] = strconv.Itoa(config.Min)
+	annotations := target.GetAnnotations()
+	if config.Max, err = strconv.Atoi(annotations[hpaOpMaxAnnotation]); err != nil {
+		return config, fmt.Errorf("invalid %s annotation on %s: %w", hpaOpMaxAnnotation, target.GetName(), err)
+	}
 
-	err = op.k8sHelper.updateDeployWithTimeout(deploy.Name, deploy, 500*time.Millisecond)
+	if config.Min, err = strconv.Atoi(annotations[hpaOpMinAnnotation]); err != nil {
+		return config, fmt.Errorf("invalid %s annotation on %s: %w", hpaOpMinAnnotation, target.GetName(), err)
+	}
 
-	return err
+	return config, nil
 }
@@ -0,0 +1,107 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// Checks the pacing options of a scale request
+func (o UpdateOptions) validate() error {
+	switch {
+	case o.Sleep < 0:
+		return errors.NewBadRequest("sleep must not be negative")
+	case o.Concurrency < 0:
+		return errors.NewBadRequest("concurrency must not be negative")
+	case o.QPS < 0 || o.Burst < 0:
+		return errors.NewBadRequest("qps and burst must not be negative")
+	case o.WavePercent < 0 || o.WavePercent > 100:
+		return errors.NewBadRequest(fmt.Sprintf("wave percent must be between 1 and 100, got %d", o.WavePercent))
+	case o.WavePercent > 0 && o.WaveInterval <= 0:
+		return errors.NewBadRequest("waves require a positive interval")
+	}
+	return nil
+}
+
+// Number of workers scaling the targets at once, never more than the targets
+func (o UpdateOptions) workers(targets int) int {
+	workers := o.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > targets {
+		workers = targets
+	}
+	return workers
+}
+
+// Limiter shared by the API requests of a job, nil when unlimited
+func (o UpdateOptions) rateLimiter() flowcontrol.RateLimiter {
+	if o.QPS <= 0 {
+		return nil
+	}
+
+	burst := o.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return flowcontrol.NewTokenBucketRateLimiter(o.QPS, burst)
+}
+
+// Spaces out the targets handed to the workers: by sleep between two targets,
+// and by the wave interval between the first targets of two waves
+type pacer struct {
+	sleep      time.Duration
+	waveSize   int
+	interval   time.Duration
+	started    int
+	waveOpened time.Time
+}
+
+func newPacer(options UpdateOptions, targets int) *pacer {
+	p := &pacer{sleep: options.Sleep, interval: options.WaveInterval}
+	if options.WavePercent > 0 {
+		// Rounded up so every wave starts at least one target
+		p.waveSize = (targets*options.WavePercent + 99) / 100
+	}
+	return p
+}
+
+// Blocks until the next target may start, failing once ctx is done
+func (p *pacer) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if p.started == 0 {
+		p.started++
+		p.waveOpened = time.Now()
+		return nil
+	}
+
+	delay := p.sleep
+	newWave := p.waveSize > 0 && p.started%p.waveSize == 0
+	if newWave {
+		delay = time.Until(p.waveOpened.Add(p.interval))
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if newWave {
+		p.waveOpened = time.Now()
+	}
+	p.started++
+	return nil
+}
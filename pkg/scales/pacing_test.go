@@ -0,0 +1,118 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUpdateOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options UpdateOptions
+		valid   bool
+	}{
+		{"Defaults", UpdateOptions{}, true},
+		{"Waves", UpdateOptions{Concurrency: 10, QPS: 20, Burst: 40, WavePercent: 10, WaveInterval: time.Minute}, true},
+		{"NegativeConcurrency", UpdateOptions{Concurrency: -1}, false},
+		{"NegativeQPS", UpdateOptions{QPS: -1}, false},
+		{"PercentAbove100", UpdateOptions{WavePercent: 120, WaveInterval: time.Minute}, false},
+		{"WaveWithoutInterval", UpdateOptions{WavePercent: 10}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.validate()
+			assert.Equal(t, test.valid, err == nil, err)
+		})
+	}
+}
+
+func TestUpdateOptions_Workers(t *testing.T) {
+	assert.Equal(t, 1, UpdateOptions{}.workers(200))
+	assert.Equal(t, 20, UpdateOptions{Concurrency: 20}.workers(200))
+	assert.Equal(t, 3, UpdateOptions{Concurrency: 20}.workers(3))
+}
+
+func TestPacer_Waves(t *testing.T) {
+	pacer := newPacer(UpdateOptions{WavePercent: 20, WaveInterval: 100 * time.Millisecond}, 10)
+	assert.Equal(t, 2, pacer.waveSize)
+
+	start := time.Now()
+	var started []time.Duration
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, pacer.wait(context.TODO()))
+		started = append(started, time.Since(start))
+	}
+
+	// Two targets per wave, a wave every 100ms
+	assert.Less(t, int64(started[1]), int64(50*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(started[2]), int64(90*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(started[9]), int64(390*time.Millisecond))
+}
+
+func TestPacer_Canceled(t *testing.T) {
+	pacer := newPacer(UpdateOptions{Sleep: time.Minute}, 2)
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	assert.Nil(t, pacer.wait(ctx))
+	cancel()
+	assert.NotNil(t, pacer.wait(ctx))
+}
+
+func TestRateLimitedHelper(t *testing.T) {
+	limiter := UpdateOptions{QPS: 10, Burst: 1}.rateLimiter()
+	defer limiter.Stop()
+
+	helper := (&k8sHelper{clientset: client, dynamic: dynamicClient}).withRateLimiter(limiter)
+	name := deployMocks["NormalDeploy"].Name
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := helper.getTargetWithTimeout(context.TODO(), name, "Deployment", name, 500*time.Millisecond)
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(180*time.Millisecond))
+}
+
+func TestSubmitJob_Concurrency(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["NormalDeploy"].Name
+	configs := ScaleConfigs{}
+	for _, target := range []string{"first", "second", "third", "fourth"} {
+		configs[target] = ScaleConfig{Namespace: name, Deployment: name, Min: 3, Max: 5}
+	}
+
+	_, err := facade.SubmitJob(context.TODO(), configs, UpdateOptions{WavePercent: 10})
+	assert.NotNil(t, err)
+
+	job, err := facade.SubmitJob(context.TODO(), configs, UpdateOptions{Concurrency: 4, QPS: 100, Burst: 10})
+	assert.Nil(t, err)
+
+	job, _ = facade.WaitJob(context.TODO(), job.ID)
+	assert.Equal(t, JobSucceeded, job.Status)
+	assert.Len(t, job.Changed(), 4)
+}
+
+func TestHpaCache_ListsNamespaceOnce(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	helper := (&k8sHelper{clientset: clientset}).withHpaCache()
+
+	for _, name := range []string{"checkout", "cart", "search"} {
+		_, err := helper.getHpaForTargetWithTimeout(context.TODO(), "shop", "Deployment", name, 500*time.Millisecond)
+		assert.True(t, errors.IsNotFound(err))
+	}
+
+	lists := 0
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "horizontalpodautoscalers" {
+			lists++
+		}
+	}
+	assert.Equal(t, 1, lists)
+}
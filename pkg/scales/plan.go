@@ -0,0 +1,127 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type DryRunMode string
+
+const (
+	// Only reads the cluster
+	DryRunClient DryRunMode = "client"
+	// Also sends the updates as server-side dry runs, so admission validates them
+	DryRunServer DryRunMode = "server"
+)
+
+type ScaleBounds struct {
+	Min      int  `json:"min"`
+	Max      int  `json:"max"`
+	Replicas *int `json:"replicas,omitempty"`
+}
+
+// Change a scale request would make on a target
+type ScalePlan struct {
+	Type    string      `json:"type,omitempty"`
+	Object  string      `json:"object,omitempty"`
+	Current ScaleBounds `json:"current"`
+	Desired ScaleBounds `json:"desired"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func boundsOf(config ScaleConfig) ScaleBounds {
+	bounds := ScaleBounds{Min: config.Min, Max: config.Max}
+	if config.Type == "Replicas" {
+		replicas := config.targetReplicas()
+		bounds.Replicas = &replicas
+	}
+	return bounds
+}
+
+// Object a scaler mutates for an identified config
+func mutatedObject(config ScaleConfig) string {
+	namespace := config.targetNamespace()
+	switch config.Type {
+	case "VanillaHpa":
+		return fmt.Sprintf("HorizontalPodAutoscaler %s/%s", namespace, config.Hpa)
+	case "Keda":
+		return fmt.Sprintf("ScaledObject %s/%s", namespace, config.ScaledObject)
+	case "Replicas":
+		return fmt.Sprintf("%s %s/%s/scale", config.targetKind(), namespace, config.targetName())
+	default:
+		return fmt.Sprintf("%s %s/%s", config.targetKind(), namespace, config.targetName())
+	}
+}
+
+// Identifies every target and reports what scaling would change, without changing it
+func (s *ScalesFacade) Plan(ctx context.Context, scaleConfigs ScaleConfigs, mode DryRunMode) map[string]ScalePlan {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	plans := make(map[string]ScalePlan)
+
+	helper, scaleHelper, policy := s.scoped(ctx)
+	if mode == DryRunServer {
+		helper = helper.withDryRun()
+	}
+
+	for name, config := range scaleConfigs {
+		config.Name = name
+		wg.Add(1)
+		go func(config ScaleConfig) {
+			defer wg.Done()
+			plan := s.plan(ctx, helper, scaleHelper, policy, config, mode)
+
+			mu.Lock()
+			plans[config.Name] = plan
+			mu.Unlock()
+		}(config)
+	}
+
+	wg.Wait()
+	return plans
+}
+
+func (s *ScalesFacade) plan(ctx context.Context, helper k8sHelperInterface, scaleHelper scaleTypeHelperInterface, policy Policy, config ScaleConfig, mode DryRunMode) ScalePlan {
+	if err := scaleHelper.IdentifyHpaType(ctx, &config); err != nil {
+		return ScalePlan{Error: err.Error()}
+	}
+
+	plan := ScalePlan{
+		Type:   config.Type,
+		Object: mutatedObject(config),
+	}
+
+	// Relative changes are only known once the current bounds are read
+	if !config.relative() {
+		plan.Desired = boundsOf(config)
+	}
+
+	scaler, err := s.scalerFactory.getScaler(config.Type, helper, s.logger, policy.Timeout)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+
+	current, err := scaler.CurrentConfig(ctx, config)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+	plan.Current = ScaleBounds{Min: current.Min, Max: current.Max, Replicas: current.Replicas}
+
+	resolved, err := config.resolveCurrent(current)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+	plan.Desired = boundsOf(resolved)
+
+	if mode == DryRunServer {
+		if _, err = scaler.Scale(ctx, config); err != nil {
+			plan.Error = err.Error()
+		}
+	}
+
+	return plan
+}
@@ -0,0 +1,65 @@
+package scales
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestPlan_DoesNotScale(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["HpaOpDeploy9"].Name
+	before, _ := dynamicClient.Resource(targetKinds["Deployment"]).Namespace(name).Get(context.TODO(), name, metav1.GetOptions{})
+	plans := facade.Plan(context.TODO(), ScaleConfigs{
+		name:      {Min: 40, Max: 80},
+		"missing": {Min: 1, Max: 2},
+	}, DryRunClient)
+
+	assert.Equal(t, "HpaOperator", plans[name].Type)
+	assert.Equal(t, "Deployment "+name+"/"+name, plans[name].Object)
+	assert.Equal(t, ScaleBounds{Min: 40, Max: 80}, plans[name].Desired)
+	assert.Empty(t, plans[name].Error)
+	assert.NotEmpty(t, plans["missing"].Error)
+
+	after, _ := dynamicClient.Resource(targetKinds["Deployment"]).Namespace(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, before.GetAnnotations(), after.GetAnnotations())
+	assert.Equal(t, before.GetAnnotations()[hpaOpMinAnnotation], strconv.Itoa(plans[name].Current.Min))
+	assert.Equal(t, before.GetAnnotations()[hpaOpMaxAnnotation], strconv.Itoa(plans[name].Current.Max))
+
+	snapshots, err := facade.GetSnapshots()
+	assert.Nil(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestPlan_RelativeReplicas(t *testing.T) {
+	replicas := int32(2)
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+		Spec:       v1.DeploymentSpec{Replicas: &replicas},
+	}
+	facade := newTestFacade(withClients(fake.NewSimpleClientset(), dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)))
+
+	plans := facade.Plan(context.TODO(), ScaleConfigs{
+		"worker": {Namespace: "shop", MinChange: &RelativeChange{Factor: 3}},
+	}, DryRunClient)
+
+	assert.Empty(t, plans["worker"].Error)
+	assert.Equal(t, "Replicas", plans["worker"].Type)
+	assert.Equal(t, 6, *plans["worker"].Desired.Replicas)
+}
+
+func TestWithDryRun(t *testing.T) {
+	helper := &k8sHelper{clientset: client, dynamic: dynamicClient}
+
+	assert.Empty(t, helper.patchOptions().DryRun)
+	assert.Equal(t, []string{metav1.DryRunAll}, helper.withDryRun().(*k8sHelper).patchOptions().DryRun)
+}
@@ -0,0 +1,66 @@
+package scales
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Growth of the wait between two retries
+const retryBackoffFactor = 5
+
+// Timeout and retry policy applied to every API call
+type Policy struct {
+	Timeout time.Duration // Deadline of a single API call
+	Retries int           // Extra attempts on conflicts and transient errors, negative disables them
+	Backoff time.Duration // Wait before the first retry
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Timeout: 500 * time.Millisecond,
+		Retries: 3,
+		Backoff: 10 * time.Millisecond,
+	}
+}
+
+// Returns p with the fields set in override replacing its own
+func (p Policy) withOverride(override Policy) Policy {
+	if override.Timeout > 0 {
+		p.Timeout = override.Timeout
+	}
+	if override.Retries != 0 {
+		p.Retries = override.Retries
+	}
+	if override.Backoff > 0 {
+		p.Backoff = override.Backoff
+	}
+	return p
+}
+
+func (p Policy) backoff() wait.Backoff {
+	steps := 1
+	if p.Retries > 0 {
+		steps += p.Retries
+	}
+
+	return wait.Backoff{
+		Duration: p.Backoff,
+		Factor:   retryBackoffFactor,
+		Jitter:   0.1,
+		Steps:    steps,
+	}
+}
+
+type policyKey struct{}
+
+// Returns a context overriding the facade policy for the calls made with it
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+func policyFrom(ctx context.Context) Policy {
+	policy, _ := ctx.Value(policyKey{}).(Policy)
+	return policy
+}
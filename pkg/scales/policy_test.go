@@ -0,0 +1,49 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+func TestPolicy_WithOverride(t *testing.T) {
+	policy := DefaultPolicy().withOverride(Policy{Timeout: 2 * time.Second})
+
+	assert.Equal(t, 2*time.Second, policy.Timeout)
+	assert.Equal(t, DefaultPolicy().Retries, policy.Retries)
+	assert.Equal(t, DefaultPolicy().Backoff, policy.Backoff)
+	assert.Equal(t, 1, DefaultPolicy().withOverride(Policy{Retries: -1}).backoff().Steps)
+}
+
+func TestScoped_ContextPolicyWins(t *testing.T) {
+	facade := newTestFacade()
+	facade.policy = Policy{Timeout: time.Second, Retries: 5}
+
+	helper, _, policy := facade.scoped(WithPolicy(context.TODO(), Policy{Timeout: 3 * time.Second}))
+
+	assert.Equal(t, 3*time.Second, policy.Timeout)
+	assert.Equal(t, 5, policy.Retries)
+	assert.Equal(t, policy, helper.(*k8sHelper).policy)
+}
+
+func TestExecuteUpdate_TimeoutPerAttempt(t *testing.T) {
+	helper := &k8sHelper{clientset: client, policy: Policy{Retries: 2, Backoff: time.Millisecond}}
+
+	attempts := 0
+	err := helper.executeUpdateWithTimeout(context.TODO(), func(client kubernetes.Interface, ctx context.Context) error {
+		attempts++
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), deadline, 50*time.Millisecond)
+		return errors.NewConflict(autoscalingv1.Resource("horizontalpodautoscalers"), "checkout-hpa", fmt.Errorf("object was modified"))
+	}, 200*time.Millisecond)
+
+	assert.True(t, errors.IsConflict(err))
+	assert.Equal(t, 3, attempts)
+}
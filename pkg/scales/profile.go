@@ -0,0 +1,195 @@
+package scales
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	profileConfigMapPrefix = "pod-scaler-for-tests-profile-"
+	profileConfigMapKey    = "scaleConfigs.json"
+	profileLabel           = "pod-scaler-for-tests/profile"
+)
+
+// Named set of scale configs, applied and reverted as a whole
+type Profile struct {
+	Name         string       `json:"name"`
+	ScaleConfigs ScaleConfigs `json:"scaleConfigs"`
+}
+
+func (p Profile) validate() error {
+	if errs := validation.IsDNS1123Label(p.Name); len(errs) > 0 {
+		return errors.NewBadRequest(fmt.Sprintf("invalid profile name %q: %s", p.Name, strings.Join(errs, ", ")))
+	}
+
+	if len(p.ScaleConfigs) == 0 {
+		return errors.NewBadRequest(fmt.Sprintf("profile %s has no scale configs", p.Name))
+	}
+
+	if errs := p.ScaleConfigs.validate(field.NewPath("scaleConfigs")); len(errs) > 0 {
+		return errors.NewInvalid(profileKind, p.Name, errs)
+	}
+	return nil
+}
+
+type profileStore interface {
+	get(ctx context.Context, name string) (Profile, error)
+	list(ctx context.Context) ([]Profile, error)
+	save(ctx context.Context, profile Profile) error
+}
+
+// Persists every profile in its own labeled ConfigMap
+type configMapProfileStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	timeout   time.Duration
+}
+
+func newConfigMapProfileStore(clientset kubernetes.Interface, namespace string, timeout time.Duration) *configMapProfileStore {
+	return &configMapProfileStore{
+		clientset: clientset,
+		namespace: namespace,
+		timeout:   timeout,
+	}
+}
+
+func (s *configMapProfileStore) get(ctx context.Context, name string) (Profile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	configMap, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, profileConfigMapPrefix+name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return Profile{}, errors.NewNotFound(apiv1.Resource("profiles"), name)
+	}
+
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return profileFrom(configMap)
+}
+
+// Returns every profile, sorted by name
+func (s *configMapProfileStore) list(ctx context.Context) ([]Profile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	configMaps, err := s.clientset.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: profileLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]Profile, 0, len(configMaps.Items))
+	for i := range configMaps.Items {
+		profile, err := profileFrom(&configMaps.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// Creates the profile, or replaces it when it already exists
+func (s *configMapProfileStore) save(ctx context.Context, profile Profile) error {
+	data, err := json.Marshal(profile.ScaleConfigs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      profileConfigMapPrefix + profile.Name,
+			Namespace: s.namespace,
+			Labels:    map[string]string{profileLabel: profile.Name},
+		},
+		Data: map[string]string{profileConfigMapKey: string(data)},
+	}
+
+	_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, configMap, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+func profileFrom(configMap *apiv1.ConfigMap) (Profile, error) {
+	profile := Profile{
+		Name:         configMap.Labels[profileLabel],
+		ScaleConfigs: make(ScaleConfigs),
+	}
+
+	if data, ok := configMap.Data[profileConfigMapKey]; ok && data != "" {
+		if err := json.Unmarshal([]byte(data), &profile.ScaleConfigs); err != nil {
+			return Profile{}, fmt.Errorf("invalid profile %s: %w", profile.Name, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// Creates or replaces a profile
+func (s *ScalesFacade) SaveProfile(ctx context.Context, profile Profile) error {
+	if err := profile.validate(); err != nil {
+		return err
+	}
+	return s.profiles.save(ctx, profile)
+}
+
+func (s *ScalesFacade) GetProfile(ctx context.Context, name string) (Profile, error) {
+	return s.profiles.get(ctx, name)
+}
+
+func (s *ScalesFacade) ListProfiles(ctx context.Context) ([]Profile, error) {
+	return s.profiles.list(ctx)
+}
+
+// Resolves and validates the profile, then submits a job scaling its targets
+func (s *ScalesFacade) ApplyProfile(ctx context.Context, name string, options UpdateOptions) (Job, error) {
+	profile, err := s.profiles.get(ctx, name)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if err = s.ValidateScaleConfigs(ctx, profile.ScaleConfigs); err != nil {
+		return Job{}, err
+	}
+
+	s.logger.Infof("Applying profile %s to %d targets.\n", name, len(profile.ScaleConfigs))
+	return s.SubmitJob(ctx, profile.ScaleConfigs, options)
+}
+
+// Restores the original configs of the profile targets
+func (s *ScalesFacade) RevertProfile(ctx context.Context, name string) (ScaleConfigs, error) {
+	profile, err := s.profiles.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore treats no names as every snapshot
+	if len(profile.ScaleConfigs) == 0 {
+		return ScaleConfigs{}, nil
+	}
+
+	return s.Restore(ctx, profile.ScaleConfigs.targetKeys()...)
+}
@@ -0,0 +1,69 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigMapProfileStore_SaveAndList(t *testing.T) {
+	facade := newTestFacade()
+
+	profile := Profile{Name: "black-friday-rehearsal", ScaleConfigs: ScaleConfigs{"some-api": {Min: 20, Max: 40}}}
+	assert.Nil(t, facade.SaveProfile(context.TODO(), profile))
+
+	profile.ScaleConfigs["some-api"] = ScaleConfig{Min: 30, Max: 60}
+	assert.Nil(t, facade.SaveProfile(context.TODO(), profile))
+	assert.Nil(t, facade.SaveProfile(context.TODO(), Profile{Name: "baseline", ScaleConfigs: ScaleConfigs{"some-api": {Min: 1, Max: 3}}}))
+
+	saved, err := facade.GetProfile(context.TODO(), "black-friday-rehearsal")
+	assert.Nil(t, err)
+	assert.Equal(t, 30, saved.ScaleConfigs["some-api"].Min)
+
+	profiles, err := facade.ListProfiles(context.TODO())
+	assert.Nil(t, err)
+	assert.Len(t, profiles, 2)
+	assert.Equal(t, "baseline", profiles[0].Name)
+
+	_, err = facade.GetProfile(context.TODO(), "unknown")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSaveProfile_Invalid(t *testing.T) {
+	facade := newTestFacade()
+
+	assert.True(t, errors.IsBadRequest(facade.SaveProfile(context.TODO(), Profile{Name: "Black Friday", ScaleConfigs: ScaleConfigs{"some-api": {}}})))
+	assert.True(t, errors.IsBadRequest(facade.SaveProfile(context.TODO(), Profile{Name: "empty"})))
+}
+
+func TestApplyAndRevertProfile(t *testing.T) {
+	facade := newTestFacade()
+	name := deployMocks["NormalDeploy"].Name
+	assert.Nil(t, facade.SaveProfile(context.TODO(), Profile{Name: "rehearsal", ScaleConfigs: ScaleConfigs{name: {Min: 7, Max: 9}}}))
+
+	original, err := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	job, err := facade.ApplyProfile(context.TODO(), "rehearsal", UpdateOptions{})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		job, _ = facade.GetJob(job.ID)
+		return job.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, JobSucceeded, job.Status)
+
+	scaled, _ := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, int32(7), *scaled.Spec.MinReplicas)
+
+	restored, err := facade.RevertProfile(context.TODO(), "rehearsal")
+	assert.Nil(t, err)
+	assert.Contains(t, restored, name+"/Deployment/"+name)
+
+	reverted, _ := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, *original.Spec.MinReplicas, *reverted.Spec.MinReplicas)
+	assert.Equal(t, original.Spec.MaxReplicas, reverted.Spec.MaxReplicas)
+}
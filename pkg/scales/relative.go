@@ -0,0 +1,124 @@
+package scales
+
+import (
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Changes a bound relative to its live value. Set one of factor, delta or percentage.
+type RelativeChange struct {
+	Factor     float64 `json:"factor,omitempty"`     // 3 triples the live value
+	Delta      int     `json:"delta,omitempty"`      // 5 adds five replicas, negative values remove
+	Percentage float64 `json:"percentage,omitempty"` // 50 adds half of the live value, negative values remove
+	Floor      *int    `json:"floor,omitempty"`      // Lowest value the change can resolve to
+	Ceiling    *int    `json:"ceiling,omitempty"`    // Highest value the change can resolve to
+}
+
+// Resolves the change against the live value, rounding to the nearest replica
+func (r RelativeChange) apply(path *field.Path, live int) (int, error) {
+	if errs := r.validate(path); len(errs) > 0 {
+		return 0, errs.ToAggregate()
+	}
+
+	var value int
+	switch {
+	case r.Factor != 0:
+		value = int(math.Round(float64(live) * r.Factor))
+	case r.Delta != 0:
+		value = live + r.Delta
+	default:
+		value = int(math.Round(float64(live) * (1 + r.Percentage/100)))
+	}
+
+	if r.Floor != nil && value < *r.Floor {
+		value = *r.Floor
+	}
+	if r.Ceiling != nil && value > *r.Ceiling {
+		value = *r.Ceiling
+	}
+	return value, nil
+}
+
+// Whether min or max are given relative to their live values
+func (c ScaleConfig) relative() bool {
+	return c.MinChange != nil || c.MaxChange != nil
+}
+
+// Returns the config with its relative changes dropped, keeping min and max as they are
+func (c ScaleConfig) absolute() ScaleConfig {
+	c.MinChange, c.MaxChange = nil, nil
+	return c
+}
+
+// Returns the config with its relative changes resolved against the live min and max.
+// A max left at zero keeps its live value. A zero min is a valid bound, so next to a
+// relative max it is refused as ambiguous rather than taken as either value.
+func (c ScaleConfig) resolve(min, max int) (ScaleConfig, error) {
+	if !c.relative() {
+		return c, nil
+	}
+
+	var err error
+	resolved := c
+	resolved.MinChange, resolved.MaxChange = nil, nil
+
+	switch {
+	case c.MinChange != nil:
+		if resolved.Min, err = c.MinChange.apply(field.NewPath("minChange"), min); err != nil {
+			return c, fmt.Errorf("invalid config of %s: %w", c.Name, err)
+		}
+	case c.Min == 0:
+		return c, fmt.Errorf("%s has a relative max and min 0, set min or keep the live one with a minChange factor of 1", c.Name)
+	}
+
+	switch {
+	case c.MaxChange != nil:
+		if resolved.Max, err = c.MaxChange.apply(field.NewPath("maxChange"), max); err != nil {
+			return c, fmt.Errorf("invalid config of %s: %w", c.Name, err)
+		}
+	case c.Max == 0:
+		resolved.Max = max
+	}
+
+	if resolved.Min < 0 || resolved.Min > resolved.Max {
+		return c, fmt.Errorf("%s resolved to min %d and max %d, min must be between 0 and max", c.Name, resolved.Min, resolved.Max)
+	}
+	return resolved, nil
+}
+
+// Resolves relative changes against the config read by the scaler of the target:
+// the replica count for workloads without any HPA, the live min and max otherwise
+func (c ScaleConfig) resolveCurrent(current ScaleConfig) (ScaleConfig, error) {
+	if current.Type == "Replicas" && current.Replicas != nil {
+		return c.resolveReplicas(*current.Replicas)
+	}
+	return c.resolve(current.Min, current.Max)
+}
+
+// Returns the config with its replica count resolved against the live one. Workloads
+// without an autoscaler have a single bound, so minChange applies when set, maxChange otherwise.
+func (c ScaleConfig) resolveReplicas(replicas int) (ScaleConfig, error) {
+	change, path := c.MinChange, field.NewPath("minChange")
+	if change == nil {
+		change, path = c.MaxChange, field.NewPath("maxChange")
+	}
+
+	resolved := c.absolute()
+	if change == nil {
+		return resolved, nil
+	}
+
+	value, err := change.apply(path, replicas)
+	if err != nil {
+		return c, fmt.Errorf("invalid config of %s: %w", c.Name, err)
+	}
+	if value < 0 {
+		return c, fmt.Errorf("%s resolved to %d replicas, replicas must not be negative", c.Name, value)
+	}
+
+	resolved.Replicas = &value
+	resolved.Min, resolved.Max = value, value
+	return resolved, nil
+}
@@ -0,0 +1,207 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestRelativeChange_Apply(t *testing.T) {
+	floor, ceiling := 4, 12
+
+	tests := []struct {
+		name     string
+		change   RelativeChange
+		live     int
+		expected int
+		valid    bool
+	}{
+		{"Factor", RelativeChange{Factor: 3}, 3, 9, true},
+		{"FactorRounds", RelativeChange{Factor: 1.5}, 3, 5, true},
+		{"Delta", RelativeChange{Delta: 5}, 10, 15, true},
+		{"NegativeDelta", RelativeChange{Delta: -2}, 10, 8, true},
+		{"Percentage", RelativeChange{Percentage: 50}, 10, 15, true},
+		{"Floor", RelativeChange{Percentage: -90, Floor: &floor}, 10, 4, true},
+		{"Ceiling", RelativeChange{Factor: 10, Ceiling: &ceiling}, 3, 12, true},
+		{"NoMode", RelativeChange{Floor: &floor}, 3, 0, false},
+		{"TwoModes", RelativeChange{Factor: 2, Delta: 1}, 3, 0, false},
+		{"FloorAboveCeiling", RelativeChange{Delta: 1, Floor: &ceiling, Ceiling: &floor}, 3, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.change.apply(field.NewPath("minChange"), test.live)
+			assert.Equal(t, test.valid, err == nil, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestScaleConfig_Resolve(t *testing.T) {
+	config := ScaleConfig{Name: "checkout", MinChange: &RelativeChange{Factor: 3}}
+	resolved, err := config.resolve(2, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, resolved.Min)
+	assert.Equal(t, 10, resolved.Max)
+	assert.Nil(t, resolved.MinChange)
+
+	config = ScaleConfig{Name: "checkout", MaxChange: &RelativeChange{Delta: 5}, Min: 4}
+	resolved, err = config.resolve(2, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, resolved.Min)
+	assert.Equal(t, 15, resolved.Max)
+
+	_, err = ScaleConfig{Name: "checkout", MinChange: &RelativeChange{Factor: 10}}.resolve(2, 10)
+	assert.NotNil(t, err)
+
+	// Zero is a valid min, it is not taken as the live one
+	_, err = ScaleConfig{Name: "checkout", MaxChange: &RelativeChange{Delta: 5}}.resolve(2, 10)
+	assert.NotNil(t, err)
+
+	absolute := ScaleConfig{Name: "checkout", Min: 30, Max: 50}
+	resolved, err = absolute.resolve(2, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, absolute, resolved)
+}
+
+func TestVanillaScale_Relative(t *testing.T) {
+	minReplicas := int32(2)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-hpa", Namespace: "shop"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout", APIVersion: "apps/v1"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(hpa)
+	scaler := newVanillaHpa(&k8sHelper{clientset: clientset}, &fakeLogger, 500*time.Millisecond)
+
+	applied, err := scaler.Scale(context.TODO(), ScaleConfig{
+		Name:      "checkout",
+		Namespace: "shop",
+		Hpa:       "checkout-hpa",
+		MinChange: &RelativeChange{Factor: 3},
+		MaxChange: &RelativeChange{Percentage: 50},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, applied.Min)
+	assert.Equal(t, 15, applied.Max)
+
+	updated, err := clientset.AutoscalingV1().HorizontalPodAutoscalers("shop").Get(context.TODO(), "checkout-hpa", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(6), *updated.Spec.MinReplicas)
+	assert.Equal(t, int32(15), updated.Spec.MaxReplicas)
+}
+
+func TestHpaOperatorScale_Relative(t *testing.T) {
+	ceiling := 10
+	deploy := &v1.Deployment{
+		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cart",
+			Namespace:   "shop",
+			Annotations: map[string]string{hpaOpMinAnnotation: "2", hpaOpMaxAnnotation: "8"},
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)
+	scaler := newHpaOperator(&k8sHelper{clientset: fake.NewSimpleClientset(), dynamic: dynamicClient}, &fakeLogger, 500*time.Millisecond)
+
+	applied, err := scaler.Scale(context.TODO(), ScaleConfig{
+		Name:      "cart",
+		Namespace: "shop",
+		MinChange: &RelativeChange{Factor: 1},
+		MaxChange: &RelativeChange{Delta: 5, Ceiling: &ceiling},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, applied.Min)
+	assert.Equal(t, 10, applied.Max)
+
+	updated, err := dynamicClient.Resource(targetKinds["Deployment"]).Namespace("shop").Get(context.TODO(), "cart", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", updated.GetAnnotations()[hpaOpMinAnnotation])
+	assert.Equal(t, "10", updated.GetAnnotations()[hpaOpMaxAnnotation])
+}
+
+func TestReplicaScale_Relative(t *testing.T) {
+	replicas := int32(3)
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+		Spec:       v1.DeploymentSpec{Replicas: &replicas},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)
+	scaler := newReplicaScaler(&k8sHelper{clientset: fake.NewSimpleClientset(), dynamic: dynamicClient}, &fakeLogger, 500*time.Millisecond)
+
+	// Raising the count would fail a min <= max check against the live count
+	applied, err := scaler.Scale(context.TODO(), ScaleConfig{
+		Name:      "worker",
+		Namespace: "shop",
+		MinChange: &RelativeChange{Factor: 2},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, *applied.Replicas)
+	assert.Nil(t, applied.MinChange)
+
+	updated, err := dynamicClient.Resource(targetKinds["Deployment"]).Namespace("shop").Get(context.TODO(), "worker", metav1.GetOptions{})
+	assert.Nil(t, err)
+	count, _, _ := unstructured.NestedInt64(updated.Object, "spec", "replicas")
+	assert.Equal(t, int64(6), count)
+
+	_, err = scaler.Scale(context.TODO(), ScaleConfig{
+		Name:      "worker",
+		Namespace: "shop",
+		MaxChange: &RelativeChange{Delta: -10},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestRestore_AfterRelativeScale(t *testing.T) {
+	deploy := &v1.Deployment{
+		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cart",
+			Namespace:   "shop",
+			Annotations: map[string]string{hpaOpMinAnnotation: "1", hpaOpMaxAnnotation: "3"},
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)
+	facade := newTestFacade(withClients(fake.NewSimpleClientset(), dynamicClient))
+
+	job := facade.RunJob(context.TODO(), ScaleConfigs{
+		"cart": {Namespace: "shop", MinChange: &RelativeChange{Factor: 2}, MaxChange: &RelativeChange{Factor: 3}},
+	}, UpdateOptions{})
+	assert.Equal(t, JobSucceeded, job.Status)
+
+	annotations := func() map[string]string {
+		updated, err := dynamicClient.Resource(targetKinds["Deployment"]).Namespace("shop").Get(context.TODO(), "cart", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return updated.GetAnnotations()
+	}
+	assert.Equal(t, "2", annotations()[hpaOpMinAnnotation])
+	assert.Equal(t, "9", annotations()[hpaOpMaxAnnotation])
+
+	snapshots, err := facade.GetSnapshots()
+	assert.Nil(t, err)
+	assert.Nil(t, snapshots["shop/Deployment/cart"].Original.MinChange)
+	assert.Nil(t, snapshots["shop/Deployment/cart"].Original.MaxChange)
+
+	_, err = facade.Restore(context.TODO(), "shop/Deployment/cart")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", annotations()[hpaOpMinAnnotation])
+	assert.Equal(t, "3", annotations()[hpaOpMaxAnnotation])
+}
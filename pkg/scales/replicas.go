@@ -0,0 +1,58 @@
+package scales
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Implements Scaler Interface, for workloads without any HPA
+type replicaScaler struct {
+	logger    *logrus.Logger
+	timeout   time.Duration
+	k8sHelper k8sHelperInterface
+}
+
+func newReplicaScaler(k8sHelper k8sHelperInterface, logger *logrus.Logger, timeout time.Duration) *replicaScaler {
+	return &replicaScaler{
+		logger:    logger,
+		timeout:   timeout,
+		k8sHelper: k8sHelper,
+	}
+}
+
+// Relative changes resolve against the current replica count
+func (r *replicaScaler) Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	if config.relative() {
+		current, err := r.CurrentConfig(ctx, config)
+		if err != nil {
+			return config, err
+		}
+
+		if config, err = config.resolveCurrent(current); err != nil {
+			return config, err
+		}
+	}
+
+	replicas := config.targetReplicas()
+	r.logger.Debugf("Setting %s replicas to %d.\n", config.Name, replicas)
+
+	return config, r.k8sHelper.setReplicasWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), int32(replicas), r.timeout)
+}
+
+// Returns config with replicas, min and max set to the current replica count, typed Replicas
+func (r *replicaScaler) CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	replicas, err := r.k8sHelper.getReplicasWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), r.timeout)
+
+	if err != nil {
+		return config, err
+	}
+
+	current := int(replicas)
+	config.Replicas = &current
+	config.Type = "Replicas"
+	config.Min = current
+	config.Max = current
+	return config, nil
+}
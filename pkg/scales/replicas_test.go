@@ -0,0 +1,74 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newReplicasFixture() (*k8sHelper, *dynamicfake.FakeDynamicClient) {
+	replicas := int32(3)
+	deploy := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "some-api-2", Namespace: "some-api-2"},
+		Spec:       v1.DeploymentSpec{Replicas: &replicas},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deploy)
+	helper := &k8sHelper{
+		clientset: fake.NewSimpleClientset(),
+		dynamic:   dynamicClient,
+	}
+	return helper, dynamicClient
+}
+
+func TestIdentifyHpaType_NoHpa(t *testing.T) {
+	helper, _ := newReplicasFixture()
+
+	config := ScaleConfig{Name: "some-api-2", Min: 5, Max: 10}
+	err := newScaleTypeHelper(helper, &fakeLogger, 500*time.Millisecond).IdentifyHpaType(context.TODO(), &config)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Replicas", config.Type)
+	assert.Empty(t, config.Hpa)
+}
+
+func TestReplicaScaler_Scale(t *testing.T) {
+	helper, dynamicClient := newReplicasFixture()
+	scaler := newReplicaScaler(helper, &fakeLogger, 500*time.Millisecond)
+	config := ScaleConfig{Name: "some-api-2", Min: 5, Max: 10, Type: "Replicas"}
+
+	current, err := scaler.CurrentConfig(context.TODO(), config)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, *current.Replicas)
+
+	replicas := 8
+	config.Replicas = &replicas
+	_, err = scaler.Scale(context.TODO(), config)
+	assert.Nil(t, err)
+
+	deploy, err := dynamicClient.Resource(targetKinds["Deployment"]).Namespace("some-api-2").Get(context.TODO(), "some-api-2", metav1.GetOptions{})
+	assert.Nil(t, err)
+	updated, _, _ := unstructured.NestedInt64(deploy.Object, "spec", "replicas")
+	assert.Equal(t, int64(8), updated)
+}
+
+func TestReplicaScaler_DefaultsToMin(t *testing.T) {
+	helper, _ := newReplicasFixture()
+	scaler := newReplicaScaler(helper, &fakeLogger, 500*time.Millisecond)
+
+	_, err := scaler.Scale(context.TODO(), ScaleConfig{Name: "some-api-2", Min: 5, Max: 10, Type: "Replicas"})
+	assert.Nil(t, err)
+
+	current, err := scaler.CurrentConfig(context.TODO(), ScaleConfig{Name: "some-api-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, *current.Replicas)
+}
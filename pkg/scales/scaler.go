@@ -1,29 +1,94 @@
 package scales
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	v1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 //go:generate mockgen --destination=./scaler_mock.go -source=./scaler.go -package=scales -self_package=github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales
 type scaler interface {
-	Scale(config ScaleConfig) error
+	// Returns the config as applied, with relative changes resolved
+	Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error)
+	CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error)
 }
 
 type scaleTypeHelperInterface interface {
-	IdentifyHpaType(scaleConfig *ScaleConfig) error
+	IdentifyHpaType(ctx context.Context, scaleConfig *ScaleConfig) error
 }
 
 type ScaleConfigs map[string]ScaleConfig
 
 type ScaleConfig struct {
-	Name        string `json:"name"`
-	Min         int    `json:"min"`
-	Max         int    `json:"max"`
-	HpaOperator bool   `json:"hpaOperator,omitempty"`
-	Type        string `json:"type,omitempty"`
+	Name         string          `json:"name"`
+	Namespace    string          `json:"namespace,omitempty"`
+	Kind         string          `json:"kind,omitempty"`
+	Target       string          `json:"target,omitempty"`     // Name of the target workload, of any kind
+	Deployment   string          `json:"deployment,omitempty"` // Alias of target, read when target is empty
+	Hpa          string          `json:"hpa,omitempty"`
+	ScaledObject string          `json:"scaledObject,omitempty"` // KEDA ScaledObject, resolved through the HPA when empty
+	Min          int             `json:"min"`
+	Max          int             `json:"max"`
+	MinChange    *RelativeChange `json:"minChange,omitempty"` // Min relative to its live value, replaces min
+	MaxChange    *RelativeChange `json:"maxChange,omitempty"` // Max relative to its live value, replaces max
+	Replicas     *int            `json:"replicas,omitempty"`  // Set on workloads without any HPA, defaults to min
+	HpaOperator  bool            `json:"hpaOperator,omitempty"`
+	Type         string          `json:"type,omitempty"`
+}
+
+// Namespace of the target, falls back to the config name
+func (c ScaleConfig) targetNamespace() string {
+	if c.Namespace != "" {
+		return c.Namespace
+	}
+	return c.Name
+}
+
+// Kind of the target workload, Deployment by default
+func (c ScaleConfig) targetKind() string {
+	if c.Kind != "" {
+		return c.Kind
+	}
+	return defaultTargetKind
+}
+
+// Name of the target workload of any kind, falls back to the config name
+func (c ScaleConfig) targetName() string {
+	if c.Target != "" {
+		return c.Target
+	}
+	if c.Deployment != "" {
+		return c.Deployment
+	}
+	return c.Name
+}
+
+// Identity of the target workload as namespace/kind/name, the key of its snapshot
+func (c ScaleConfig) targetKey() string {
+	return fmt.Sprintf("%s/%s/%s", c.targetNamespace(), c.targetKind(), c.targetName())
+}
+
+// Identities of the targets, named after their keys in the configs
+func (c ScaleConfigs) targetKeys() []string {
+	keys := make([]string, 0, len(c))
+	for name, config := range c {
+		config.Name = name
+		keys = append(keys, config.targetKey())
+	}
+	return keys
+}
+
+// Replica count for workloads without any HPA
+func (c ScaleConfig) targetReplicas() int {
+	if c.Replicas != nil {
+		return *c.Replicas
+	}
+	return c.Min
 }
 
 type scaleTypeHelper struct {
@@ -40,21 +105,54 @@ func newScaleTypeHelper(k8sHelper k8sHelperInterface, logger *logrus.Logger, tim
 	}
 }
 
-func (s scaleTypeHelper) IdentifyHpaType(scaleConfig *ScaleConfig) error {
+func (s scaleTypeHelper) IdentifyHpaType(ctx context.Context, scaleConfig *ScaleConfig) error {
 	helper := s.k8sHelper
-	deploy, err := helper.getDeploymentWithTimeout(scaleConfig.Name, s.timeout)
+	scaleConfig.Namespace = scaleConfig.targetNamespace()
+	scaleConfig.Kind = scaleConfig.targetKind()
+	scaleConfig.Target = scaleConfig.targetName()
+	target, err := helper.getTargetWithTimeout(ctx, scaleConfig.Namespace, scaleConfig.Kind, scaleConfig.Target, s.timeout)
+
+	if err != nil {
+		return err
+	}
+
+	s.checkIfHpaOp(target, scaleConfig)
+	if scaleConfig.HpaOperator {
+		return nil
+	}
+
+	// A named HPA may still be owned by KEDA, which would revert any direct patch
+	if scaleConfig.Hpa != "" {
+		hpa, err := helper.getHpaWithTimeout(ctx, scaleConfig.Namespace, scaleConfig.Hpa, s.timeout)
+		if err != nil {
+			return err
+		}
+
+		s.checkIfKeda(hpa, scaleConfig)
+		return nil
+	}
+
+	hpa, err := helper.getHpaForTargetWithTimeout(ctx, scaleConfig.Namespace, scaleConfig.Kind, scaleConfig.Target, s.timeout)
+	if errors.IsNotFound(err) {
+		s.logger.Debugf("%s has no HPA, scaling replicas.\n", scaleConfig.Name)
+		scaleConfig.Type = "Replicas"
+		return nil
+	}
 
 	if err != nil {
 		return err
 	}
 
-	s.checkIfHpaOp(deploy, scaleConfig)
+	s.logger.Debugf("%s is scaled by HPA %s.\n", scaleConfig.Name, hpa.Name)
+	scaleConfig.Hpa = hpa.Name
+	s.checkIfKeda(hpa, scaleConfig)
 	return nil
 }
 
-func (s scaleTypeHelper) checkIfHpaOp(deploy *v1.Deployment, scaleConfig *ScaleConfig) {
-	_, maxOk := deploy.Annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"]
-	_, minOk := deploy.Annotations["hpa.autoscaling.banzaicloud.io/minReplicas"]
+func (s scaleTypeHelper) checkIfHpaOp(target *unstructured.Unstructured, scaleConfig *ScaleConfig) {
+	annotations := target.GetAnnotations()
+	_, maxOk := annotations[hpaOpMaxAnnotation]
+	_, minOk := annotations[hpaOpMinAnnotation]
 
 	if maxOk && minOk {
 		s.logger.Debugf("%s uses Hpa Operator.\n", scaleConfig.Name)
@@ -66,3 +164,11 @@ func (s scaleTypeHelper) checkIfHpaOp(deploy *v1.Deployment, scaleConfig *ScaleC
 		scaleConfig.Type = "VanillaHpa"
 	}
 }
+
+func (s scaleTypeHelper) checkIfKeda(hpa *autoscalingv2.HorizontalPodAutoscaler, scaleConfig *ScaleConfig) {
+	if name, ok := kedaScaledObjectName(hpa); ok {
+		s.logger.Debugf("%s uses KEDA ScaledObject %s.\n", scaleConfig.Name, name)
+		scaleConfig.ScaledObject = name
+		scaleConfig.Type = "Keda"
+	}
+}
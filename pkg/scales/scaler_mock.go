@@ -5,6 +5,7 @@
 package scales
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,18 +34,34 @@ func (m *Mockscaler) EXPECT() *MockscalerMockRecorder {
 	return m.recorder
 }
 
+// CurrentConfig mocks base method.
+func (m *Mockscaler) CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CurrentConfig", ctx, config)
+	ret0, _ := ret[0].(ScaleConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CurrentConfig indicates an expected call of CurrentConfig.
+func (mr *MockscalerMockRecorder) CurrentConfig(ctx, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentConfig", reflect.TypeOf((*Mockscaler)(nil).CurrentConfig), ctx, config)
+}
+
 // Scale mocks base method.
-func (m *Mockscaler) Scale(config ScaleConfig) error {
+func (m *Mockscaler) Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Scale", config)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Scale", ctx, config)
+	ret0, _ := ret[0].(ScaleConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Scale indicates an expected call of Scale.
-func (mr *MockscalerMockRecorder) Scale(config interface{}) *gomock.Call {
+func (mr *MockscalerMockRecorder) Scale(ctx, config interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scale", reflect.TypeOf((*Mockscaler)(nil).Scale), config)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scale", reflect.TypeOf((*Mockscaler)(nil).Scale), ctx, config)
 }
 
 // MockscaleTypeHelperInterface is a mock of scaleTypeHelperInterface interface.
@@ -71,15 +88,15 @@ func (m *MockscaleTypeHelperInterface) EXPECT() *MockscaleTypeHelperInterfaceMoc
 }
 
 // IdentifyHpaType mocks base method.
-func (m *MockscaleTypeHelperInterface) IdentifyHpaType(scaleConfig *ScaleConfig) error {
+func (m *MockscaleTypeHelperInterface) IdentifyHpaType(ctx context.Context, scaleConfig *ScaleConfig) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IdentifyHpaType", scaleConfig)
+	ret := m.ctrl.Call(m, "IdentifyHpaType", ctx, scaleConfig)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IdentifyHpaType indicates an expected call of IdentifyHpaType.
-func (mr *MockscaleTypeHelperInterfaceMockRecorder) IdentifyHpaType(scaleConfig interface{}) *gomock.Call {
+func (mr *MockscaleTypeHelperInterfaceMockRecorder) IdentifyHpaType(ctx, scaleConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdentifyHpaType", reflect.TypeOf((*MockscaleTypeHelperInterface)(nil).IdentifyHpaType), scaleConfig)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdentifyHpaType", reflect.TypeOf((*MockscaleTypeHelperInterface)(nil).IdentifyHpaType), ctx, scaleConfig)
 }
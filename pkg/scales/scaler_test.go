@@ -1,11 +1,15 @@
 package scales
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestIdentifyHpaType_VanillaSuccess(t *testing.T) {
@@ -19,18 +23,59 @@ func TestIdentifyHpaType_VanillaSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	hpaMock := autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "normal-deploy-hpa"},
+	}
+
 	m := NewMockk8sHelperInterface(ctrl)
 	m.
 		EXPECT().
-		getDeploymentWithTimeout(gomock.Any(), gomock.Any()).
-		Return(&deployMock, nil)
+		getTargetWithTimeout(gomock.Any(), vanillaScaleConfig.Name, "Deployment", vanillaScaleConfig.Name, gomock.Any()).
+		Return(toUnstructured(&deployMock), nil)
+	m.
+		EXPECT().
+		getHpaForTargetWithTimeout(gomock.Any(), vanillaScaleConfig.Name, "Deployment", vanillaScaleConfig.Name, gomock.Any()).
+		Return(&hpaMock, nil)
 
-	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500)
-	err := scaleHelper.IdentifyHpaType(&vanillaScaleConfig)
+	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500*time.Millisecond)
+	err := scaleHelper.IdentifyHpaType(context.TODO(), &vanillaScaleConfig)
 
 	assert.Empty(t, err)
 	assert.Equal(t, false, vanillaScaleConfig.HpaOperator)
 	assert.Equal(t, "VanillaHpa", vanillaScaleConfig.Type)
+	assert.Equal(t, "normal-deploy-hpa", vanillaScaleConfig.Hpa)
+}
+
+func TestIdentifyHpaType_ExplicitTarget(t *testing.T) {
+	scaleConfig := ScaleConfig{
+		Name:       "checkout",
+		Namespace:  "shop",
+		Deployment: "checkout-api",
+		Hpa:        "checkout-api-hpa",
+		Min:        30,
+		Max:        50,
+	}
+	deployMock := deployMocks["NormalDeploy"]
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := NewMockk8sHelperInterface(ctrl)
+	m.
+		EXPECT().
+		getTargetWithTimeout(gomock.Any(), "shop", "Deployment", "checkout-api", gomock.Any()).
+		Return(toUnstructured(&deployMock), nil)
+	m.
+		EXPECT().
+		getHpaWithTimeout(gomock.Any(), "shop", "checkout-api-hpa", gomock.Any()).
+		Return(&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "checkout-api-hpa", Namespace: "shop"}}, nil)
+
+	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500*time.Millisecond)
+	err := scaleHelper.IdentifyHpaType(context.TODO(), &scaleConfig)
+
+	assert.Empty(t, err)
+	assert.Equal(t, "VanillaHpa", scaleConfig.Type)
+	assert.Equal(t, "checkout-api-hpa", scaleConfig.Hpa)
 }
 
 func TestIdentifyHpaType_OperatorSuccess(t *testing.T) {
@@ -47,11 +92,11 @@ func TestIdentifyHpaType_OperatorSuccess(t *testing.T) {
 	m := NewMockk8sHelperInterface(ctrl)
 	m.
 		EXPECT().
-		getDeploymentWithTimeout(gomock.Any(), gomock.Any()).
-		Return(&deployMock, nil)
+		getTargetWithTimeout(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(toUnstructured(&deployMock), nil)
 
-	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500)
-	err := scaleHelper.IdentifyHpaType(&vanillaScaleConfig)
+	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500*time.Millisecond)
+	err := scaleHelper.IdentifyHpaType(context.TODO(), &vanillaScaleConfig)
 
 	assert.Empty(t, err)
 	assert.Equal(t, true, vanillaScaleConfig.HpaOperator)
@@ -71,11 +116,11 @@ func TestIdentifyHpaType_Fail(t *testing.T) {
 	m := NewMockk8sHelperInterface(ctrl)
 	m.
 		EXPECT().
-		getDeploymentWithTimeout(gomock.Any(), gomock.Any()).
+		getTargetWithTimeout(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil, fmt.Errorf("Fake error"))
 
-	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500)
-	err := scaleHelper.IdentifyHpaType(&vanillaScaleConfig)
+	scaleHelper := newScaleTypeHelper(m, &fakeLogger, 500*time.Millisecond)
+	err := scaleHelper.IdentifyHpaType(context.TODO(), &vanillaScaleConfig)
 
 	assert.NotNil(t, err)
 }
@@ -89,13 +134,13 @@ func TestScaleHpaOperator_Success(t *testing.T) {
 
 	k8sHelperMock.
 		EXPECT().
-		getDeploymentWithTimeout(gomock.Any(), gomock.Any()).
-		Return(&fakeDeploy, nil).
+		getTargetWithTimeout(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(toUnstructured(&fakeDeploy), nil).
 		AnyTimes()
 
 	k8sHelperMock.
 		EXPECT().
-		updateDeployWithTimeout(gomock.Any(), gomock.Any(), gomock.Any()).
+		annotateTargetWithTimeout(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), map[string]string{hpaOpMaxAnnotation: "5", hpaOpMinAnnotation: "3"}, gomock.Any()).
 		Return(nil).
 		AnyTimes()
 
@@ -105,6 +150,6 @@ func TestScaleHpaOperator_Success(t *testing.T) {
 		HpaOperator: true,
 	}
 
-	hpaOp := newHpaOperator(k8sHelperMock, &fakeLogger)
-	hpaOp.Scale(config)
+	hpaOp := newHpaOperator(k8sHelperMock, &fakeLogger, 500*time.Millisecond)
+	hpaOp.Scale(context.TODO(), config)
 }
@@ -12,14 +12,20 @@ import (
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 var (
-	fakeLogger  logrus.Logger
-	deployMocks map[string]v1.Deployment
-	client      = fake.NewSimpleClientset()
+	fakeLogger    logrus.Logger
+	deployMocks   map[string]v1.Deployment
+	client        = fake.NewSimpleClientset()
+	dynamicClient *dynamicfake.FakeDynamicClient
 
 	fakeDeploymentModel = v1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -98,9 +104,11 @@ func init() {
 
 	deployMocks["NormalDeploy"] = fakeDeploy
 
+	dynamicClient = dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
 	for _, deploy := range deployMocks {
 		fmt.Printf("Creating mock deploy %s.\n", deploy.Name)
-		if _, err := client.AppsV1().Deployments(deploy.Name).Create(context.TODO(), &deploy, metav1.CreateOptions{}); err != nil {
+		deploy.Namespace = deploy.Name
+		if _, err := dynamicClient.Resource(targetKinds["Deployment"]).Namespace(deploy.Name).Create(context.TODO(), toUnstructured(&deploy), metav1.CreateOptions{}); err != nil {
 			panic("Unable to create mocks")
 		}
 		respectiveHpa := fakeHpaModel
@@ -117,6 +125,42 @@ func init() {
 	fakeLogger.Level = logrus.DebugLevel
 }
 
+func toUnstructured(obj runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		panic(err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+type testFacadeOption func(facade *ScalesFacade)
+
+// Builds a facade on the shared fake clients, each store backed by its own fake ConfigMap
+func newTestFacade(options ...testFacadeOption) *ScalesFacade {
+	facade := &ScalesFacade{
+		k8sHelper:     &k8sHelper{clientset: client, dynamic: dynamicClient},
+		scalerFactory: &scalerFactory{},
+		snapshots:     newConfigMapSnapshotStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond),
+		profiles:      newConfigMapProfileStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond),
+		schedules:     newConfigMapScheduleStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond),
+		scheduler:     newScheduler(),
+		jobs:          NewJobStore(),
+		logger:        &fakeLogger,
+	}
+
+	for _, option := range options {
+		option(facade)
+	}
+	return facade
+}
+
+// Replaces the shared fake clients, for tests needing their own objects
+func withClients(clientset kubernetes.Interface, dynamic dynamic.Interface) testFacadeOption {
+	return func(facade *ScalesFacade) {
+		facade.k8sHelper = &k8sHelper{clientset: clientset, dynamic: dynamic}
+	}
+}
+
 // TODO: Refactor
 func TestUpdateHpaOperatorSuccess(t *testing.T) {
 	// scaleConfigs := &ScaleConfig{
@@ -134,13 +178,14 @@ func TestUpdateHpaOperatorSuccess(t *testing.T) {
 func TestUpdateMultipleHpaWithConcurrencySuccess(t *testing.T) {
 	k8sHelper := &k8sHelper{
 		clientset: client,
-		ctx:       context.TODO(),
+		dynamic:   dynamicClient,
 	}
 
 	facade := &ScalesFacade{
 		k8sHelper:     k8sHelper,
-		scaleHelper:   newScaleTypeHelper(k8sHelper, &fakeLogger, 500),
 		scalerFactory: &scalerFactory{},
+		snapshots:     newConfigMapSnapshotStore(client, "default", 500*time.Millisecond),
+		jobs:          NewJobStore(),
 		logger:        &fakeLogger,
 	}
 	scaleConfigs := ScaleConfigs{
@@ -203,21 +248,22 @@ func TestUpdateMultipleHpaWithConcurrencySuccess(t *testing.T) {
 
 	sleep := time.Duration(time.Second * 1)
 	// facade.UpdateHpaWithConcurrency(client, scaleConfigs, &fakeLogger, &sleep)
-	facade.UpdateWithConcurrency(scaleConfigs, &sleep)
+	facade.UpdateWithConcurrency(context.TODO(), scaleConfigs, &sleep)
 
-	checkIfUpdated(scaleConfigs, client, t)
+	checkIfUpdated(scaleConfigs, dynamicClient, t)
 }
 
-func checkIfUpdated(scaleConfigs ScaleConfigs, client kubernetes.Interface, t *testing.T) {
+func checkIfUpdated(scaleConfigs ScaleConfigs, client dynamic.Interface, t *testing.T) {
 	for name, config := range scaleConfigs {
-		deploy, _ := client.AppsV1().Deployments(name).Get(context.TODO(), name, metav1.GetOptions{})
+		deploy, _ := client.Resource(targetKinds["Deployment"]).Namespace(name).Get(context.TODO(), name, metav1.GetOptions{})
+		annotations := deploy.GetAnnotations()
 
-		if config.HpaOperator && deploy.Annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"] != strconv.Itoa(config.Max) {
-			t.Errorf("%s max not updated: %s\n", name, deploy.Annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"])
+		if config.HpaOperator && annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"] != strconv.Itoa(config.Max) {
+			t.Errorf("%s max not updated: %s\n", name, annotations["hpa.autoscaling.banzaicloud.io/maxReplicas"])
 		}
 
-		if config.HpaOperator && deploy.Annotations["hpa.autoscaling.banzaicloud.io/minReplicas"] != strconv.Itoa(config.Min) {
-			t.Errorf("%s min not updated: %s\n", name, deploy.Annotations["hpa.autoscaling.banzaicloud.io/minReplicas"])
+		if config.HpaOperator && annotations["hpa.autoscaling.banzaicloud.io/minReplicas"] != strconv.Itoa(config.Min) {
+			t.Errorf("%s min not updated: %s\n", name, annotations["hpa.autoscaling.banzaicloud.io/minReplicas"])
 		}
 	}
 }
@@ -0,0 +1,211 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var ScaleTestResource = schema.GroupVersionResource{Group: "pod-scaler-for-tests.io", Version: "v1alpha1", Resource: "scaletests"}
+
+// Finalizer keeping a running ScaleTest until its targets are restored
+const ScaleTestFinalizer = "pod-scaler-for-tests.io/restore"
+
+// Wait before retrying a restore that failed
+const scaleTestRestoreRetry = 30 * time.Second
+
+type ScaleTestPhase string
+
+const (
+	ScaleTestPending   ScaleTestPhase = "Pending"
+	ScaleTestRunning   ScaleTestPhase = "Running"
+	ScaleTestCompleted ScaleTestPhase = "Completed"
+	ScaleTestFailed    ScaleTestPhase = "Failed"
+)
+
+// Condition types reported on a ScaleTest
+const (
+	ScaleTestScaled   = "Scaled"
+	ScaleTestRestored = "Restored"
+)
+
+// Scale configs applied at start time and restored once duration elapses
+type ScaleTest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ScaleTestSpec   `json:"spec"`
+	Status            ScaleTestStatus `json:"status,omitempty"`
+}
+
+type ScaleTestSpec struct {
+	Targets   ScaleConfigs    `json:"targets"`
+	StartTime *metav1.Time    `json:"startTime,omitempty"` // Starts right away when empty
+	Duration  metav1.Duration `json:"duration"`
+	Sleep     metav1.Duration `json:"sleep,omitempty"` // Pause between scaling two targets
+}
+
+type ScaleTestStatus struct {
+	Phase              ScaleTestPhase       `json:"phase,omitempty"`
+	ObservedGeneration int64                `json:"observedGeneration,omitempty"`
+	ScaledAt           *metav1.Time         `json:"scaledAt,omitempty"`
+	RestoredAt         *metav1.Time         `json:"restoredAt,omitempty"`
+	Targets            map[string]JobTarget `json:"targets,omitempty"`
+	Conditions         []metav1.Condition   `json:"conditions,omitempty"`
+}
+
+func ScaleTestFromUnstructured(obj *unstructured.Unstructured) (*ScaleTest, error) {
+	test := &ScaleTest{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, test); err != nil {
+		return nil, fmt.Errorf("invalid ScaleTest %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return test, nil
+}
+
+// Copies the status into obj
+func (t *ScaleTest) SetStatusOn(obj *unstructured.Unstructured) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&t.Status)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(obj.Object, status, "status")
+}
+
+// Whether the ScaleTest no longer changes any target
+func (t *ScaleTest) Finished() bool {
+	return t.Status.Phase == ScaleTestCompleted || t.Status.Phase == ScaleTestFailed
+}
+
+func (t *ScaleTest) startTime() time.Time {
+	if t.Spec.StartTime != nil {
+		return t.Spec.StartTime.Time
+	}
+	return t.CreationTimestamp.Time
+}
+
+func (t *ScaleTest) targetNames() []string {
+	names := make([]string, 0, len(t.Spec.Targets))
+	for name := range t.Spec.Targets {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (t *ScaleTest) setCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&t.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: t.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// Moves the ScaleTest through its phases: scales the targets once start time is
+// reached and restores them when the duration elapses or the ScaleTest is deleted.
+// Returns when it should be reconciled again, zero when only changes matter.
+func (s *ScalesFacade) ReconcileScaleTest(ctx context.Context, test *ScaleTest) time.Duration {
+	if test.Finished() {
+		return 0
+	}
+
+	test.Status.ObservedGeneration = test.Generation
+	if len(test.Spec.Targets) == 0 || test.Spec.Duration.Duration <= 0 {
+		test.Status.Phase = ScaleTestFailed
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "InvalidSpec", "targets and a positive duration are required")
+		return 0
+	}
+
+	if errs := test.Spec.Targets.validate(field.NewPath("spec", "targets")); len(errs) > 0 && test.Status.Phase != ScaleTestRunning {
+		test.Status.Phase = ScaleTestFailed
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "InvalidSpec", errs.ToAggregate().Error())
+		return 0
+	}
+
+	now := time.Now()
+	start := test.startTime()
+	end := start.Add(test.Spec.Duration.Duration)
+
+	if test.Status.Phase == ScaleTestRunning {
+		if test.DeletionTimestamp == nil && now.Before(end) {
+			return end.Sub(now)
+		}
+		return s.restoreScaleTest(ctx, test)
+	}
+
+	if test.DeletionTimestamp != nil {
+		test.Status.Phase = ScaleTestCompleted
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "Deleted", "deleted before start time")
+		return 0
+	}
+
+	if !now.Before(end) {
+		test.Status.Phase = ScaleTestFailed
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "WindowElapsed", "the test window ended before it could start")
+		return 0
+	}
+
+	if now.Before(start) {
+		test.Status.Phase = ScaleTestPending
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "Pending", fmt.Sprintf("waiting for %s", start.Format(time.RFC3339)))
+		return start.Sub(now)
+	}
+
+	s.logger.Infof("ScaleTest %s/%s started, scaling %d targets.\n", test.Namespace, test.Name, len(test.Spec.Targets))
+	job := s.RunJob(ctx, test.Spec.Targets, UpdateOptions{Sleep: test.Spec.Sleep.Duration})
+
+	scaledAt := metav1.Now()
+	test.Status.Phase = ScaleTestRunning
+	test.Status.ScaledAt = &scaledAt
+	test.Status.Targets = make(map[string]JobTarget, len(job.Targets))
+	for name, target := range job.Targets {
+		test.Status.Targets[name] = *target
+	}
+
+	if job.Status == JobSucceeded {
+		test.setCondition(ScaleTestScaled, metav1.ConditionTrue, "Scaled", "every target was scaled")
+	} else {
+		test.setCondition(ScaleTestScaled, metav1.ConditionFalse, "PartiallyScaled", fmt.Sprintf("%d of %d targets were scaled", len(job.Changed()), len(job.Targets)))
+	}
+
+	return time.Until(end)
+}
+
+// Restores the targets the ScaleTest scaled, unless they were already restored by other means
+func (s *ScalesFacade) restoreScaleTest(ctx context.Context, test *ScaleTest) time.Duration {
+	snapshots, err := s.snapshots.list()
+	if err != nil {
+		test.setCondition(ScaleTestRestored, metav1.ConditionFalse, "RestoreFailed", err.Error())
+		return scaleTestRestoreRetry
+	}
+
+	var keys []string
+	for _, name := range test.targetNames() {
+		config := test.Spec.Targets[name]
+		config.Name = name
+		if _, ok := snapshots[config.targetKey()]; ok && test.Status.Targets[name].Status == TargetScaled {
+			keys = append(keys, config.targetKey())
+		}
+	}
+
+	if len(keys) > 0 {
+		if _, err := s.Restore(ctx, keys...); err != nil {
+			s.logger.Errorf("Unable to restore ScaleTest %s/%s: %s\n", test.Namespace, test.Name, err)
+			test.setCondition(ScaleTestRestored, metav1.ConditionFalse, "RestoreFailed", err.Error())
+			return scaleTestRestoreRetry
+		}
+	}
+
+	restoredAt := metav1.Now()
+	test.Status.RestoredAt = &restoredAt
+	test.Status.Phase = ScaleTestCompleted
+	test.setCondition(ScaleTestRestored, metav1.ConditionTrue, "Restored", "original configs were restored")
+	s.logger.Infof("ScaleTest %s/%s finished, targets restored.\n", test.Namespace, test.Name)
+	return 0
+}
@@ -0,0 +1,92 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestScaleTestFromUnstructured(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "pod-scaler-for-tests.io/v1alpha1",
+		"kind":       "ScaleTest",
+		"metadata":   map[string]interface{}{"name": "rehearsal", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"targets":   map[string]interface{}{"some-api": map[string]interface{}{"min": int64(20), "max": int64(40)}},
+			"startTime": "2021-11-26T08:00:00Z",
+			"duration":  "30m",
+		},
+	}}
+
+	test, err := ScaleTestFromUnstructured(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Minute, test.Spec.Duration.Duration)
+	assert.Equal(t, 20, test.Spec.Targets["some-api"].Min)
+	assert.Equal(t, 2021, test.Spec.StartTime.Year())
+
+	test.Status.Phase = ScaleTestRunning
+	test.setCondition(ScaleTestScaled, metav1.ConditionTrue, "Scaled", "every target was scaled")
+	assert.Nil(t, test.SetStatusOn(obj))
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	assert.Equal(t, string(ScaleTestRunning), phase)
+}
+
+func TestReconcileScaleTest_Pending(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(time.Hour))
+	test := &ScaleTest{Spec: ScaleTestSpec{
+		Targets:   ScaleConfigs{"some-api": {Min: 20, Max: 40}},
+		StartTime: &start,
+		Duration:  metav1.Duration{Duration: time.Hour},
+	}}
+
+	requeue := newTestFacade().ReconcileScaleTest(context.TODO(), test)
+
+	assert.Equal(t, ScaleTestPending, test.Status.Phase)
+	assert.InDelta(t, time.Hour.Seconds(), requeue.Seconds(), 1)
+}
+
+func TestReconcileScaleTest_Invalid(t *testing.T) {
+	test := &ScaleTest{Spec: ScaleTestSpec{Targets: ScaleConfigs{"some-api": {}}}}
+
+	assert.Zero(t, newTestFacade().ReconcileScaleTest(context.TODO(), test))
+	assert.Equal(t, ScaleTestFailed, test.Status.Phase)
+	assert.True(t, test.Finished())
+}
+
+func TestReconcileScaleTest_ScalesAndRestoresOnDelete(t *testing.T) {
+	facade := newTestFacade()
+	name := deployMocks["NormalDeploy"].Name
+	original, _ := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+
+	test := &ScaleTest{
+		ObjectMeta: metav1.ObjectMeta{Name: "rehearsal", Namespace: "default", CreationTimestamp: metav1.Now()},
+		Spec: ScaleTestSpec{
+			Targets:  ScaleConfigs{name: {Min: 11, Max: 13}},
+			Duration: metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	requeue := facade.ReconcileScaleTest(context.TODO(), test)
+	assert.Equal(t, ScaleTestRunning, test.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(test.Status.Conditions, ScaleTestScaled))
+	assert.InDelta(t, time.Hour.Seconds(), requeue.Seconds(), 5)
+
+	scaled, _ := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, int32(11), *scaled.Spec.MinReplicas)
+
+	deletedAt := metav1.Now()
+	test.DeletionTimestamp = &deletedAt
+	assert.Zero(t, facade.ReconcileScaleTest(context.TODO(), test))
+	assert.Equal(t, ScaleTestCompleted, test.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(test.Status.Conditions, ScaleTestRestored))
+
+	restored, _ := client.AutoscalingV1().HorizontalPodAutoscalers(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, *original.Spec.MinReplicas, *restored.Spec.MinReplicas)
+	assert.Equal(t, original.Spec.MaxReplicas, restored.Spec.MaxReplicas)
+}
@@ -0,0 +1,316 @@
+package scales
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	scheduleConfigMapName = "pod-scaler-for-tests-schedules"
+	scheduleConfigMapKey  = "schedules.json"
+)
+
+// Scale window opened on every cron match, or once between start and end.
+// Targets are scaled when the window opens and restored when it closes.
+type Schedule struct {
+	ID           string          `json:"id"`
+	ScaleConfigs ScaleConfigs    `json:"scaleConfigs"`
+	Cron         string          `json:"cron,omitempty"`     // Standard expression, CRON_TZ= prefix supported
+	Duration     metav1.Duration `json:"duration,omitempty"` // Length of every cron window
+	Start        *time.Time      `json:"start,omitempty"`
+	End          *time.Time      `json:"end,omitempty"`
+	Sleep        metav1.Duration `json:"sleep,omitempty"` // Pause between scaling two targets
+	CreatedAt    time.Time       `json:"createdAt"`
+	LastRun      *time.Time      `json:"lastRun,omitempty"`
+	LastJobID    string          `json:"lastJobId,omitempty"`
+}
+
+type Schedules map[string]Schedule
+
+func (s Schedule) validate(now time.Time) error {
+	if len(s.ScaleConfigs) == 0 {
+		return errors.NewBadRequest("schedule has no scale configs")
+	}
+
+	switch {
+	case s.Cron != "" && (s.Start != nil || s.End != nil):
+		return errors.NewBadRequest("schedule takes either cron or start and end, not both")
+	case s.Cron != "":
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return errors.NewBadRequest(fmt.Sprintf("invalid cron %q: %s", s.Cron, err))
+		}
+		if s.Duration.Duration <= 0 {
+			return errors.NewBadRequest("cron schedule requires a positive duration")
+		}
+	case s.Start != nil && s.End != nil:
+		if !s.Start.Before(*s.End) {
+			return errors.NewBadRequest("schedule start must be before end")
+		}
+		if !now.Before(*s.End) {
+			return errors.NewBadRequest("schedule end has already passed")
+		}
+	default:
+		return errors.NewBadRequest("schedule requires cron or start and end")
+	}
+
+	if errs := s.ScaleConfigs.validate(field.NewPath("scaleConfigs")); len(errs) > 0 {
+		return errors.NewInvalid(scheduleKind, s.ID, errs)
+	}
+	return nil
+}
+
+// When the window opened at now closes
+func (s Schedule) windowEnd(now time.Time) time.Time {
+	if s.Cron != "" {
+		return now.Add(s.Duration.Duration)
+	}
+	return *s.End
+}
+
+type scheduleStore interface {
+	list() (Schedules, error)
+	update(f func(schedules Schedules) error) error
+}
+
+// Persists schedules as JSON inside a ConfigMap so they survive restarts
+type configMapScheduleStore struct {
+	*configMapDocument
+}
+
+func newConfigMapScheduleStore(clientset kubernetes.Interface, namespace string, timeout time.Duration) *configMapScheduleStore {
+	return &configMapScheduleStore{
+		configMapDocument: newConfigMapDocument(clientset, namespace, scheduleConfigMapName, scheduleConfigMapKey, timeout),
+	}
+}
+
+func (s *configMapScheduleStore) list() (Schedules, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return decodeSchedules(data)
+}
+
+// Applies f to the stored schedules and persists the result
+func (s *configMapScheduleStore) update(f func(schedules Schedules) error) error {
+	return s.modify(func(data string) (string, error) {
+		schedules, err := decodeSchedules(data)
+		if err != nil {
+			return "", err
+		}
+
+		if err = f(schedules); err != nil {
+			return "", err
+		}
+
+		encoded, err := json.Marshal(schedules)
+		return string(encoded), err
+	})
+}
+
+func decodeSchedules(data string) (Schedules, error) {
+	schedules := make(Schedules)
+	if data == "" {
+		return schedules, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Fires armed schedules, in memory only: schedules are armed again from the store on startup
+type scheduler struct {
+	mu    sync.Mutex
+	cron  *cron.Cron
+	stops map[string]func()
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		cron:  cron.New(),
+		stops: make(map[string]func()),
+	}
+}
+
+// Calls run on every window start of the schedule, replacing a previous arming
+func (s *scheduler) arm(schedule Schedule, now time.Time, run func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disarmLocked(schedule.ID)
+	if schedule.Cron != "" {
+		spec, err := cron.ParseStandard(schedule.Cron)
+		if err != nil {
+			return err
+		}
+
+		id := s.cron.Schedule(spec, cron.FuncJob(run))
+		s.stops[schedule.ID] = func() { s.cron.Remove(id) }
+		return nil
+	}
+
+	// Single windows run once, even when a restart happens while they are open
+	if schedule.LastRun != nil || !now.Before(*schedule.End) {
+		return nil
+	}
+
+	timer := time.AfterFunc(schedule.Start.Sub(now), run)
+	s.stops[schedule.ID] = func() { timer.Stop() }
+	return nil
+}
+
+func (s *scheduler) disarm(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disarmLocked(id)
+}
+
+func (s *scheduler) disarmLocked(id string) {
+	if stop, ok := s.stops[id]; ok {
+		stop()
+		delete(s.stops, id)
+	}
+}
+
+// Validates, persists and arms a new schedule, returning it with its ID
+func (s *ScalesFacade) CreateSchedule(schedule Schedule) (Schedule, error) {
+	now := time.Now()
+	if err := schedule.validate(now); err != nil {
+		return Schedule{}, err
+	}
+
+	schedule.ID = rand.String(10)
+	schedule.CreatedAt = now
+	schedule.LastRun = nil
+	schedule.LastJobID = ""
+
+	err := s.schedules.update(func(schedules Schedules) error {
+		schedules[schedule.ID] = schedule
+		return nil
+	})
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	if err = s.armSchedule(schedule, now); err != nil {
+		return Schedule{}, err
+	}
+
+	s.logger.Infof("Schedule %s created for %d targets.\n", schedule.ID, len(schedule.ScaleConfigs))
+	return schedule, nil
+}
+
+// Returns every schedule, oldest first
+func (s *ScalesFacade) ListSchedules() ([]Schedule, error) {
+	schedules, err := s.schedules.list()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		list = append(list, schedule)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Stops and removes a schedule. Targets of an open window are still restored when it closes.
+func (s *ScalesFacade) DeleteSchedule(id string) error {
+	err := s.schedules.update(func(schedules Schedules) error {
+		if _, ok := schedules[id]; !ok {
+			return errors.NewNotFound(apiv1.Resource("schedules"), id)
+		}
+		delete(schedules, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.scheduler.disarm(id)
+	s.logger.Infof("Schedule %s deleted.\n", id)
+	return nil
+}
+
+// Arms the schedules persisted by a previous run and starts firing them.
+// Cron windows missed while the tool was down are skipped.
+func (s *ScalesFacade) ResumeSchedules() error {
+	schedules, err := s.schedules.list()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		if err := s.armSchedule(schedule, now); err != nil {
+			s.logger.Errorf("Unable to arm schedule %s: %s\n", schedule.ID, err)
+		}
+	}
+
+	s.scheduler.cron.Start()
+	return nil
+}
+
+func (s *ScalesFacade) armSchedule(schedule Schedule, now time.Time) error {
+	id := schedule.ID
+	return s.scheduler.arm(schedule, now, func() { s.runSchedule(id) })
+}
+
+// Opens a window of the schedule: scales its targets and restores them when the window closes
+func (s *ScalesFacade) runSchedule(id string) {
+	schedules, err := s.schedules.list()
+	if err != nil {
+		s.logger.Errorf("Unable to run schedule %s: %s\n", id, err)
+		return
+	}
+
+	// Deleted after it was armed
+	schedule, ok := schedules[id]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	ttl := schedule.windowEnd(now).Sub(now)
+	if ttl <= 0 {
+		return
+	}
+
+	job, err := s.SubmitJob(context.Background(), schedule.ScaleConfigs, UpdateOptions{Sleep: schedule.Sleep.Duration, TTL: ttl})
+	if err != nil {
+		s.logger.Errorf("Unable to run schedule %s: %s\n", id, err)
+		return
+	}
+	s.logger.Infof("Schedule %s started job %s, restoring in %s.\n", id, job.ID, ttl)
+
+	err = s.schedules.update(func(schedules Schedules) error {
+		if schedule, ok := schedules[id]; ok {
+			schedule.LastRun = &now
+			schedule.LastJobID = job.ID
+			schedules[id] = schedule
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("Unable to record run of schedule %s: %s\n", id, err)
+	}
+}
@@ -0,0 +1,100 @@
+package scales
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	configs := ScaleConfigs{"some-api": {Min: 2, Max: 4}}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		valid    bool
+	}{
+		{"Cron", Schedule{ScaleConfigs: configs, Cron: "0 22 * * 1-5", Duration: metav1.Duration{Duration: time.Hour}}, true},
+		{"CronWithTimezone", Schedule{ScaleConfigs: configs, Cron: "CRON_TZ=America/Sao_Paulo @daily", Duration: metav1.Duration{Duration: time.Hour}}, true},
+		{"CronWithoutDuration", Schedule{ScaleConfigs: configs, Cron: "0 22 * * *"}, false},
+		{"InvalidCron", Schedule{ScaleConfigs: configs, Cron: "every night", Duration: metav1.Duration{Duration: time.Hour}}, false},
+		{"Window", Schedule{ScaleConfigs: configs, Start: &now, End: &future}, true},
+		{"WindowEnded", Schedule{ScaleConfigs: configs, Start: &past, End: &past}, false},
+		{"CronAndWindow", Schedule{ScaleConfigs: configs, Cron: "@daily", Duration: metav1.Duration{Duration: time.Hour}, Start: &now, End: &future}, false},
+		{"NoTargets", Schedule{Cron: "@daily", Duration: metav1.Duration{Duration: time.Hour}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schedule.validate(now)
+			assert.Equal(t, test.valid, err == nil, err)
+			if err != nil {
+				assert.True(t, errors.IsBadRequest(err))
+			}
+		})
+	}
+}
+
+func TestCreateSchedule_PersistsAndDeletes(t *testing.T) {
+	facade := newTestFacade()
+
+	schedule, err := facade.CreateSchedule(Schedule{
+		ScaleConfigs: ScaleConfigs{"some-api": {Min: 2, Max: 4}},
+		Cron:         "0 22 * * *",
+		Duration:     metav1.Duration{Duration: time.Hour},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, schedule.ID)
+	assert.Contains(t, facade.scheduler.stops, schedule.ID)
+
+	// A restarted tool finds the schedule in the store
+	restarted := newTestFacade()
+	restarted.schedules = facade.schedules
+	assert.Nil(t, restarted.ResumeSchedules())
+	defer restarted.scheduler.cron.Stop()
+	assert.Contains(t, restarted.scheduler.stops, schedule.ID)
+
+	schedules, err := facade.ListSchedules()
+	assert.Nil(t, err)
+	assert.Len(t, schedules, 1)
+	assert.Equal(t, "0 22 * * *", schedules[0].Cron)
+
+	assert.Nil(t, facade.DeleteSchedule(schedule.ID))
+	assert.NotContains(t, facade.scheduler.stops, schedule.ID)
+	assert.True(t, errors.IsNotFound(facade.DeleteSchedule(schedule.ID)))
+
+	schedules, err = facade.ListSchedules()
+	assert.Nil(t, err)
+	assert.Empty(t, schedules)
+}
+
+func TestCreateSchedule_RunsWindow(t *testing.T) {
+	facade := newTestFacade()
+
+	start, end := time.Now(), time.Now().Add(time.Hour)
+	schedule, err := facade.CreateSchedule(Schedule{
+		ScaleConfigs: ScaleConfigs{"some-api": {Name: "some-api", Min: 2, Max: 4}},
+		Start:        &start,
+		End:          &end,
+	})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		schedules, err := facade.ListSchedules()
+		return err == nil && schedules[0].LastJobID != ""
+	}, time.Second, 10*time.Millisecond)
+
+	schedules, _ := facade.ListSchedules()
+	_, ok := facade.GetJob(schedules[0].LastJobID)
+	assert.True(t, ok)
+
+	// Single windows that already ran are not armed again on restart
+	assert.Nil(t, facade.ResumeSchedules())
+	defer facade.scheduler.cron.Stop()
+	assert.NotContains(t, facade.scheduler.stops, schedule.ID)
+}
@@ -0,0 +1,147 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Picks targets by labels and annotations instead of listing their names
+type TargetSelector struct {
+	Namespaces        []string          `json:"namespaces,omitempty"`        // Every namespace when empty
+	NamespaceSelector string            `json:"namespaceSelector,omitempty"` // Label selector on namespaces
+	Kind              string            `json:"kind,omitempty"`              // Kind of the selected workloads, Deployment by default
+	Selector          string            `json:"selector,omitempty"`          // Label selector on workloads
+	HpaSelector       string            `json:"hpaSelector,omitempty"`       // Label selector on HPAs, selecting the workloads they scale
+	Annotations       map[string]string `json:"annotations,omitempty"`       // Required on the selected objects, an empty value matches any
+}
+
+// Scale bounds applied to every target a selector resolves to
+type Selection struct {
+	Selector TargetSelector `json:"selector"`
+	Min      int            `json:"min"`
+	Max      int            `json:"max"`
+	Replicas *int           `json:"replicas,omitempty"`
+}
+
+func (t TargetSelector) validate() error {
+	if t.Selector == "" && t.HpaSelector == "" && len(t.Annotations) == 0 {
+		return errors.NewBadRequest("selector requires selector, hpaSelector or annotations")
+	}
+
+	if t.Selector != "" && t.HpaSelector != "" {
+		return errors.NewBadRequest("selector takes either selector or hpaSelector, not both")
+	}
+
+	if t.HpaSelector != "" && t.Kind != "" {
+		return errors.NewBadRequest("kind comes from the HPAs when selecting by hpaSelector")
+	}
+
+	for field, selector := range map[string]string{"namespaceSelector": t.NamespaceSelector, "selector": t.Selector, "hpaSelector": t.HpaSelector} {
+		if _, err := labels.Parse(selector); err != nil {
+			return errors.NewBadRequest(fmt.Sprintf("invalid %s %q: %s", field, selector, err))
+		}
+	}
+	return nil
+}
+
+func (t TargetSelector) matchesAnnotations(annotations map[string]string) bool {
+	for key, value := range t.Annotations {
+		actual, ok := annotations[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selection) config(namespace, kind, name string) ScaleConfig {
+	return ScaleConfig{
+		Name:      fmt.Sprintf("%s/%s", namespace, name),
+		Namespace: namespace,
+		Kind:      kind,
+		Target:    name,
+		Min:       s.Min,
+		Max:       s.Max,
+		Replicas:  s.Replicas,
+	}
+}
+
+// Expands the selection into concrete targets, keyed by namespace/name
+func (s *ScalesFacade) SelectTargets(ctx context.Context, selection Selection) (ScaleConfigs, error) {
+	selector := selection.Selector
+	if err := selector.validate(); err != nil {
+		return nil, err
+	}
+
+	helper, _, policy := s.scoped(ctx)
+	namespaces, err := selectNamespaces(ctx, helper, selector, policy.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(ScaleConfigs)
+	for _, namespace := range namespaces {
+		if selector.HpaSelector != "" {
+			hpas, err := helper.listHpasWithTimeout(ctx, namespace, selector.HpaSelector, policy.Timeout)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, hpa := range hpas {
+				if selector.matchesAnnotations(hpa.Annotations) {
+					config := selection.config(hpa.Namespace, hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name)
+					configs[config.Name] = config
+				}
+			}
+			continue
+		}
+
+		kind := ScaleConfig{Kind: selector.Kind}.targetKind()
+		targets, err := helper.listTargetsWithTimeout(ctx, namespace, kind, selector.Selector, policy.Timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			if selector.matchesAnnotations(target.GetAnnotations()) {
+				config := selection.config(target.GetNamespace(), kind, target.GetName())
+				configs[config.Name] = config
+			}
+		}
+	}
+
+	s.logger.Debugf("Selector resolved to %d targets.\n", len(configs))
+	return configs, nil
+}
+
+// Namespaces to list targets in, a single empty name standing for every namespace
+func selectNamespaces(ctx context.Context, helper k8sHelperInterface, selector TargetSelector, timeout time.Duration) ([]string, error) {
+	if selector.NamespaceSelector == "" {
+		if len(selector.Namespaces) == 0 {
+			return []string{""}, nil
+		}
+		return selector.Namespaces, nil
+	}
+
+	matching, err := helper.listNamespacesWithTimeout(ctx, selector.NamespaceSelector, timeout)
+	if err != nil || len(selector.Namespaces) == 0 {
+		return matching, err
+	}
+
+	allowed := make(map[string]bool, len(selector.Namespaces))
+	for _, namespace := range selector.Namespaces {
+		allowed[namespace] = true
+	}
+
+	var namespaces []string
+	for _, namespace := range matching {
+		if allowed[namespace] {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,116 @@
+package scales
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newSelectorFacade() *ScalesFacade {
+	deploy := func(namespace, name string, labels, annotations map[string]string) *v1.Deployment {
+		return &v1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, Annotations: annotations},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Labels: map[string]string{"team": "payments"}}},
+		&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "wallet", Labels: map[string]string{"team": "payments"}}},
+		&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search", Labels: map[string]string{"team": "discovery"}}},
+		&autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet-api", Namespace: "wallet", Labels: map[string]string{"load-test": "true"}},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "StatefulSet", Name: "wallet-db", APIVersion: "apps/v1"},
+				MaxReplicas:    6,
+			},
+		},
+	)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+		deploy("checkout", "checkout-api", map[string]string{"tier": "api"}, map[string]string{"load-test/enabled": "true"}),
+		deploy("checkout", "checkout-worker", map[string]string{"tier": "worker"}, nil),
+		deploy("wallet", "wallet-api", map[string]string{"tier": "api"}, nil),
+		deploy("search", "search-api", map[string]string{"tier": "api"}, map[string]string{"load-test/enabled": "false"}),
+	)
+
+	return newTestFacade(withClients(clientset, dynamicClient))
+}
+
+func TestSelectTargets_NamespaceAndWorkloadSelectors(t *testing.T) {
+	facade := newSelectorFacade()
+
+	configs, err := facade.SelectTargets(context.TODO(), Selection{
+		Selector: TargetSelector{NamespaceSelector: "team=payments", Selector: "tier=api"},
+		Min:      10,
+		Max:      20,
+	})
+
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, ScaleConfig{Name: "checkout/checkout-api", Namespace: "checkout", Kind: "Deployment", Target: "checkout-api", Min: 10, Max: 20}, configs["checkout/checkout-api"])
+	assert.Contains(t, configs, "wallet/wallet-api")
+}
+
+func TestSelectTargets_Annotations(t *testing.T) {
+	facade := newSelectorFacade()
+
+	configs, err := facade.SelectTargets(context.TODO(), Selection{
+		Selector: TargetSelector{Annotations: map[string]string{"load-test/enabled": "true"}},
+		Min:      2,
+		Max:      4,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"checkout/checkout-api"}, keys(configs))
+
+	configs, err = facade.SelectTargets(context.TODO(), Selection{
+		Selector: TargetSelector{Namespaces: []string{"search"}, Annotations: map[string]string{"load-test/enabled": ""}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"search/search-api"}, keys(configs))
+}
+
+func TestSelectTargets_HpaSelector(t *testing.T) {
+	facade := newSelectorFacade()
+
+	configs, err := facade.SelectTargets(context.TODO(), Selection{
+		Selector: TargetSelector{HpaSelector: "load-test=true"},
+		Min:      3,
+		Max:      9,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "StatefulSet", configs["wallet/wallet-db"].Kind)
+	assert.Equal(t, "wallet-db", configs["wallet/wallet-db"].Target)
+}
+
+func TestSelectTargets_Invalid(t *testing.T) {
+	facade := newSelectorFacade()
+
+	for _, selector := range []TargetSelector{
+		{},
+		{NamespaceSelector: "team=payments"},
+		{Selector: "tier=api", HpaSelector: "load-test=true"},
+		{Selector: "tier in (api"},
+	} {
+		_, err := facade.SelectTargets(context.TODO(), Selection{Selector: selector})
+		assert.True(t, errors.IsBadRequest(err), selector)
+	}
+}
+
+func keys(configs ScaleConfigs) []string {
+	var names []string
+	for name := range configs {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+package scales
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	snapshotConfigMapName = "pod-scaler-for-tests-snapshots"
+	snapshotConfigMapKey  = "snapshots.json"
+)
+
+// Original scale config of a target, taken before it was first changed
+type Snapshot struct {
+	Original  ScaleConfig `json:"original"`
+	TakenAt   time.Time   `json:"takenAt"`
+	RestoreAt *time.Time  `json:"restoreAt,omitempty"`
+}
+
+type Snapshots map[string]Snapshot
+
+type snapshotStore interface {
+	list() (Snapshots, error)
+	update(f func(snapshots Snapshots) error) error
+}
+
+// Persists snapshots as JSON inside a ConfigMap so they survive restarts
+type configMapSnapshotStore struct {
+	*configMapDocument
+}
+
+func newConfigMapSnapshotStore(clientset kubernetes.Interface, namespace string, timeout time.Duration) *configMapSnapshotStore {
+	return &configMapSnapshotStore{
+		configMapDocument: newConfigMapDocument(clientset, namespace, snapshotConfigMapName, snapshotConfigMapKey, timeout),
+	}
+}
+
+// Namespace where the tool stores its state, defaults to "default"
+func currentNamespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+func (s *configMapSnapshotStore) list() (Snapshots, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshots(data)
+}
+
+// Applies f to the stored snapshots and persists the result
+func (s *configMapSnapshotStore) update(f func(snapshots Snapshots) error) error {
+	return s.modify(func(data string) (string, error) {
+		snapshots, err := decodeSnapshots(data)
+		if err != nil {
+			return "", err
+		}
+
+		if err = f(snapshots); err != nil {
+			return "", err
+		}
+
+		encoded, err := json.Marshal(snapshots)
+		return string(encoded), err
+	})
+}
+
+func decodeSnapshots(data string) (Snapshots, error) {
+	snapshots := make(Snapshots)
+	if data == "" {
+		return snapshots, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
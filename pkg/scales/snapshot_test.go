@@ -0,0 +1,95 @@
+package scales
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapSnapshotStore_Persists(t *testing.T) {
+	store := newConfigMapSnapshotStore(fake.NewSimpleClientset(), "default", 500*time.Millisecond)
+
+	err := store.update(func(snapshots Snapshots) error {
+		snapshots["some-api"] = Snapshot{Original: ScaleConfig{Name: "some-api", Min: 2, Max: 10, Type: "VanillaHpa"}}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	snapshots, err := store.list()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, snapshots["some-api"].Original.Min)
+	assert.Equal(t, 10, snapshots["some-api"].Original.Max)
+
+	configMap, err := store.clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), snapshotConfigMapName, metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Contains(t, configMap.Data[snapshotConfigMapKey], "some-api")
+}
+
+func TestRecordSnapshot_KeepsOriginal(t *testing.T) {
+	facade := newTestFacade()
+
+	config := ScaleConfig{Name: "some-api", Min: 30, Max: 50, Type: "VanillaHpa"}
+	assert.Nil(t, facade.recordSnapshot(config, ScaleConfig{Name: "some-api", Min: 2, Max: 10, Type: "VanillaHpa"}))
+	assert.Nil(t, facade.recordSnapshot(config, ScaleConfig{Name: "some-api", Min: 30, Max: 50, Type: "VanillaHpa"}))
+
+	snapshots, err := facade.GetSnapshots()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, snapshots["some-api/Deployment/some-api"].Original.Min)
+	assert.Equal(t, 10, snapshots["some-api/Deployment/some-api"].Original.Max)
+}
+
+func TestRestore_Success(t *testing.T) {
+	facade := newTestFacade()
+
+	name := deployMocks["HpaOpDeploy10"].Name
+	sleep := time.Duration(0)
+	facade.UpdateWithConcurrency(context.TODO(), ScaleConfigs{name: {Min: 20, Max: 40}}, &sleep)
+
+	key := name + "/Deployment/" + name
+	restored, err := facade.Restore(context.TODO(), key)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, restored[key].Min)
+	assert.Equal(t, 3, restored[key].Max)
+
+	deploy, _ := dynamicClient.Resource(targetKinds["Deployment"]).Namespace(name).Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Equal(t, "1", deploy.GetAnnotations()[hpaOpMinAnnotation])
+	assert.Equal(t, "3", deploy.GetAnnotations()[hpaOpMaxAnnotation])
+
+	snapshots, err := facade.GetSnapshots()
+	assert.Nil(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestRestore_MissingSnapshot(t *testing.T) {
+	facade := newTestFacade()
+
+	_, err := facade.Restore(context.TODO(), "unknown")
+	assert.NotNil(t, err)
+}
+
+func TestRecordSnapshot_KeyedByTarget(t *testing.T) {
+	facade := newTestFacade()
+
+	record := func(config ScaleConfig) {
+		original := config
+		original.Min, original.Max = 2, 10
+		assert.Nil(t, facade.recordSnapshot(config, original))
+	}
+
+	// Same request key, different targets
+	record(ScaleConfig{Name: "api", Namespace: "shop"})
+	record(ScaleConfig{Name: "api", Namespace: "billing"})
+	// Same target, different request key
+	record(ScaleConfig{Name: "shop/api", Namespace: "shop", Deployment: "api"})
+
+	snapshots, err := facade.GetSnapshots()
+	assert.Nil(t, err)
+	assert.Len(t, snapshots, 2)
+	assert.Contains(t, snapshots, "shop/Deployment/api")
+	assert.Contains(t, snapshots, "billing/Deployment/api")
+	assert.Equal(t, "api", snapshots["shop/Deployment/api"].Original.Name)
+}
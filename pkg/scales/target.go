@@ -0,0 +1,48 @@
+package scales
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const defaultTargetKind = "Deployment"
+
+// Well known kinds exposing the scale subresource
+var targetKinds = map[string]schema.GroupVersionResource{
+	"Deployment":  {Group: "apps", Version: "v1", Resource: "deployments"},
+	"StatefulSet": {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"ReplicaSet":  {Group: "apps", Version: "v1", Resource: "replicasets"},
+	"Rollout":     {Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"},
+}
+
+// Finds the resource of a kind that is not well known, as long as it exposes the scale subresource
+func discoverTargetKind(client discovery.DiscoveryInterface, kind string) (schema.GroupVersionResource, error) {
+	_, resourceLists, err := client.ServerGroupsAndResources()
+	if err != nil && len(resourceLists) == 0 {
+		return schema.GroupVersionResource{}, err
+	}
+
+	for _, resourceList := range resourceLists {
+		scalable := map[string]bool{}
+		for _, resource := range resourceList.APIResources {
+			if strings.HasSuffix(resource.Name, "/scale") {
+				scalable[strings.TrimSuffix(resource.Name, "/scale")] = true
+			}
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if resource.Kind == kind && !strings.Contains(resource.Name, "/") && scalable[resource.Name] {
+				gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+				if err != nil {
+					return schema.GroupVersionResource{}, err
+				}
+				return gv.WithResource(resource.Name), nil
+			}
+		}
+	}
+
+	return schema.GroupVersionResource{}, fmt.Errorf("kind %s not found or does not expose the scale subresource", kind)
+}
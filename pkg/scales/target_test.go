@@ -0,0 +1,136 @@
+package scales
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestIdentifyHpaType_StatefulSet(t *testing.T) {
+	statefulSet := &v1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "shop"},
+	}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache-hpa", Namespace: "shop"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "StatefulSet", Name: "cache", APIVersion: "apps/v1"},
+			MaxReplicas:    5,
+		},
+	}
+
+	helper := &k8sHelper{
+		clientset: fake.NewSimpleClientset(hpa),
+		dynamic:   dynamicfake.NewSimpleDynamicClient(scheme.Scheme, statefulSet),
+	}
+
+	config := ScaleConfig{Name: "cache", Namespace: "shop", Kind: "StatefulSet", Min: 3, Max: 6}
+	err := newScaleTypeHelper(helper, &fakeLogger, 500*time.Millisecond).IdentifyHpaType(context.TODO(), &config)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "VanillaHpa", config.Type)
+	assert.Equal(t, "cache-hpa", config.Hpa)
+}
+
+func TestScaleHpaOperator_StatefulSet(t *testing.T) {
+	statefulSet := &v1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cache",
+			Namespace: "shop",
+			Annotations: map[string]string{
+				hpaOpMinAnnotation: "1",
+				hpaOpMaxAnnotation: "3",
+			},
+		},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, statefulSet)
+	helper := &k8sHelper{
+		clientset: fake.NewSimpleClientset(),
+		dynamic:   dynamicClient,
+	}
+
+	_, err := newHpaOperator(helper, &fakeLogger, 500*time.Millisecond).Scale(context.TODO(), ScaleConfig{Name: "cache", Namespace: "shop", Kind: "StatefulSet", Min: 4, Max: 8})
+	assert.Nil(t, err)
+
+	updated, err := dynamicClient.Resource(targetKinds["StatefulSet"]).Namespace("shop").Get(context.TODO(), "cache", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "4", updated.GetAnnotations()[hpaOpMinAnnotation])
+	assert.Equal(t, "8", updated.GetAnnotations()[hpaOpMaxAnnotation])
+}
+
+func TestDiscoverTargetKind(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "workers", Kind: "Worker", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "workers/scale", Kind: "Scale", Namespaced: true, Verbs: metav1.Verbs{"get", "update"}},
+				{Name: "jobs", Kind: "Job", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+	}
+
+	gvr, err := discoverTargetKind(clientset.Discovery(), "Worker")
+	assert.Nil(t, err)
+	assert.Equal(t, schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "workers"}, gvr)
+
+	_, err = discoverTargetKind(clientset.Discovery(), "Job")
+	assert.NotNil(t, err)
+}
+
+func TestDerivedHelpers_ShareDiscovery(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "workers", Kind: "Worker", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "workers/scale", Kind: "Scale", Namespaced: true, Verbs: metav1.Verbs{"get", "update"}},
+			},
+		},
+	}
+
+	helper := &k8sHelper{clientset: clientset, discovery: &discoveryCache{}}
+	_, err := helper.withPolicy(Policy{}).(*k8sHelper).targetResource("Worker")
+	assert.Nil(t, err)
+
+	// Found in the cache, without asking the server again
+	clientset.Resources = nil
+	gvr, err := helper.withDryRun().withRateLimiter(nil).(*k8sHelper).targetResource("Worker")
+	assert.Nil(t, err)
+	assert.Equal(t, "workers", gvr.Resource)
+}
+
+func TestScaleConfig_TargetName(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"Target", `{"target": "cache"}`, "cache"},
+		{"DeploymentAlias", `{"deployment": "checkout"}`, "checkout"},
+		{"TargetFirst", `{"target": "cache", "deployment": "checkout"}`, "cache"},
+		{"ConfigName", `{"name": "cart"}`, "cart"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var config ScaleConfig
+			assert.Nil(t, json.Unmarshal([]byte(test.body), &config))
+			assert.Equal(t, test.want, config.targetName())
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package scales
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Kinds reported by invalid input errors
+var (
+	scaleConfigsKind = schema.GroupKind{Group: ScaleTestResource.Group, Kind: "ScaleConfigs"}
+	profileKind      = schema.GroupKind{Group: ScaleTestResource.Group, Kind: "Profile"}
+	scheduleKind     = schema.GroupKind{Group: ScaleTestResource.Group, Kind: "Schedule"}
+)
+
+// Checks every target without calling the cluster, reporting fields under root
+func (c ScaleConfigs) validate(root *field.Path) field.ErrorList {
+	if len(c) == 0 {
+		return field.ErrorList{field.Required(root, "at least one target is required")}
+	}
+
+	var errs field.ErrorList
+	for name, config := range c {
+		path := root.Key(name)
+		if name == "" {
+			errs = append(errs, field.Required(path, "target name is required"))
+		}
+		errs = append(errs, config.validate(path)...)
+	}
+	return errs
+}
+
+func (c ScaleConfig) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if c.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(c.Namespace) {
+			errs = append(errs, field.Invalid(path.Child("namespace"), c.Namespace, msg))
+		}
+	}
+
+	if c.Target != "" && c.Deployment != "" && c.Target != c.Deployment {
+		errs = append(errs, field.Invalid(path.Child("deployment"), c.Deployment, "must match target when both are set"))
+	}
+
+	for _, object := range []struct{ name, value string }{{"target", c.Target}, {"deployment", c.Deployment}, {"hpa", c.Hpa}, {"scaledObject", c.ScaledObject}} {
+		if object.value == "" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(object.value) {
+			errs = append(errs, field.Invalid(path.Child(object.name), object.value, msg))
+		}
+	}
+
+	if c.Type != "" && !contains(scalerTypes, c.Type) {
+		errs = append(errs, field.NotSupported(path.Child("type"), c.Type, scalerTypes))
+	}
+
+	// Workloads without any HPA may be given replicas alone, leaving min and max unset
+	replicasOnly := c.Replicas != nil && !c.relative() && c.Min == 0 && c.Max == 0
+	if c.Replicas != nil {
+		errs = append(errs, c.validateReplicas(path.Child("replicas"), replicasOnly)...)
+	}
+	if replicasOnly {
+		return errs
+	}
+
+	if c.MinChange != nil {
+		errs = append(errs, c.MinChange.validate(path.Child("minChange"))...)
+	} else if c.Min < 0 {
+		errs = append(errs, field.Invalid(path.Child("min"), c.Min, "must be greater than or equal to 0"))
+	}
+
+	// A zero min next to a relative max could mean either zero or the live value
+	if c.MaxChange != nil && c.MinChange == nil && c.Min == 0 {
+		errs = append(errs, field.Invalid(path.Child("min"), c.Min, "must be set with maxChange, use a minChange factor of 1 to keep the live value"))
+	}
+
+	// A zero max keeps the live value when min changes relatively
+	if c.MaxChange != nil {
+		errs = append(errs, c.MaxChange.validate(path.Child("maxChange"))...)
+	} else if c.Max < 1 && !(c.MinChange != nil && c.Max == 0) {
+		errs = append(errs, field.Invalid(path.Child("max"), c.Max, "must be greater than or equal to 1"))
+	}
+
+	if !c.relative() && c.Min > c.Max {
+		errs = append(errs, field.Invalid(path.Child("min"), c.Min, "must be less than or equal to max"))
+	}
+
+	return errs
+}
+
+// Replicas must not be negative, and must lie within min and max when those are given
+func (c ScaleConfig) validateReplicas(path *field.Path, replicasOnly bool) field.ErrorList {
+	switch {
+	case *c.Replicas < 0:
+		return field.ErrorList{field.Invalid(path, *c.Replicas, "must be greater than or equal to 0")}
+	case !replicasOnly && !c.relative() && (*c.Replicas < c.Min || *c.Replicas > c.Max):
+		return field.ErrorList{field.Invalid(path, *c.Replicas, fmt.Sprintf("must be between min %d and max %d", c.Min, c.Max))}
+	}
+	return nil
+}
+
+func (r RelativeChange) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	modes := 0
+	for _, set := range []bool{r.Factor != 0, r.Delta != 0, r.Percentage != 0} {
+		if set {
+			modes++
+		}
+	}
+
+	if modes != 1 {
+		errs = append(errs, field.Invalid(path, r, "set exactly one of factor, delta or percentage"))
+	}
+
+	if r.Factor < 0 {
+		errs = append(errs, field.Invalid(path.Child("factor"), r.Factor, "must be greater than 0"))
+	}
+
+	if r.Floor != nil && *r.Floor < 0 {
+		errs = append(errs, field.Invalid(path.Child("floor"), *r.Floor, "must be greater than or equal to 0"))
+	}
+
+	if r.Floor != nil && r.Ceiling != nil && *r.Floor > *r.Ceiling {
+		errs = append(errs, field.Invalid(path.Child("floor"), *r.Floor, "must be less than or equal to ceiling"))
+	}
+
+	return errs
+}
+
+// Rejects invalid targets with an error listing every field at fault, checking
+// the peak replicas of the targets against the pod quota of their namespaces
+func (s *ScalesFacade) ValidateScaleConfigs(ctx context.Context, scaleConfigs ScaleConfigs) error {
+	root := field.NewPath("scaleConfigs")
+	errs := scaleConfigs.validate(root)
+	if len(errs) == 0 {
+		helper, _, policy := s.scoped(ctx)
+		errs = s.validateQuotas(ctx, helper, scaleConfigs, root, policy.Timeout)
+	}
+
+	if len(errs) > 0 {
+		return errors.NewInvalid(scaleConfigsKind, "request", errs)
+	}
+	return nil
+}
+
+// Forbids targets whose combined peak replicas exceed a pods ResourceQuota of their
+// namespace. Only the targets' own pods are counted, as other workloads may change.
+func (s *ScalesFacade) validateQuotas(ctx context.Context, helper k8sHelperInterface, scaleConfigs ScaleConfigs, root *field.Path, timeout time.Duration) field.ErrorList {
+	peaks := make(map[string]map[string]int)
+	for name, config := range scaleConfigs {
+		config.Name = name
+		peak, ok := config.peakReplicas()
+		if !ok {
+			continue
+		}
+
+		namespace := config.targetNamespace()
+		if peaks[namespace] == nil {
+			peaks[namespace] = make(map[string]int)
+		}
+		peaks[namespace][name] = peak
+	}
+
+	var errs field.ErrorList
+	for namespace, targets := range peaks {
+		quotas, err := helper.listResourceQuotasWithTimeout(ctx, namespace, timeout)
+		if err != nil {
+			s.logger.Warnf("Unable to check ResourceQuotas of %s: %s\n", namespace, err)
+			continue
+		}
+
+		total := 0
+		for _, peak := range targets {
+			total += peak
+		}
+
+		for _, quota := range quotas {
+			hard, ok := podQuota(quota)
+			if !ok || int64(total) <= hard {
+				continue
+			}
+
+			for name := range targets {
+				detail := fmt.Sprintf("targets in namespace %s could run %d pods, ResourceQuota %s allows %d", namespace, total, quota.Name, hard)
+				errs = append(errs, field.Forbidden(root.Key(name).Child("max"), detail))
+			}
+		}
+	}
+	return errs
+}
+
+// Most replicas the target can reach, unknown when max changes relatively without a ceiling
+func (c ScaleConfig) peakReplicas() (int, bool) {
+	peak := c.Max
+	if c.MaxChange != nil {
+		if c.MaxChange.Ceiling == nil {
+			return 0, false
+		}
+		peak = *c.MaxChange.Ceiling
+	}
+
+	if c.Replicas != nil && *c.Replicas > peak {
+		peak = *c.Replicas
+	}
+	return peak, true
+}
+
+// Hard pod limit of the quota, as enforced when reported in its status
+func podQuota(quota apiv1.ResourceQuota) (int64, bool) {
+	hard, ok := quota.Status.Hard[apiv1.ResourcePods]
+	if !ok {
+		hard, ok = quota.Spec.Hard[apiv1.ResourcePods]
+	}
+	return hard.Value(), ok
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
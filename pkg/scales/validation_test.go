@@ -0,0 +1,84 @@
+package scales
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleConfigs_Validate(t *testing.T) {
+	replicas, five, fifty := -1, 5, 50
+
+	tests := []struct {
+		name   string
+		config ScaleConfig
+		fields []string
+	}{
+		{"Valid", ScaleConfig{Min: 2, Max: 4}, nil},
+		{"MinAboveMax", ScaleConfig{Min: 5, Max: 4}, []string{"scaleConfigs[some-api].min"}},
+		{"Negative", ScaleConfig{Min: -1, Max: 4, Replicas: &replicas}, []string{"scaleConfigs[some-api].min", "scaleConfigs[some-api].replicas"}},
+		{"ZeroMax", ScaleConfig{}, []string{"scaleConfigs[some-api].max"}},
+		{"UnknownType", ScaleConfig{Min: 1, Max: 2, Type: "Vanilla"}, []string{"scaleConfigs[some-api].type"}},
+		{"InvalidNamespace", ScaleConfig{Namespace: "Shop", Min: 1, Max: 2}, []string{"scaleConfigs[some-api].namespace"}},
+		{"RelativeMin", ScaleConfig{MinChange: &RelativeChange{Factor: 3}}, nil},
+		{"InvalidChange", ScaleConfig{Max: 4, MinChange: &RelativeChange{Factor: 3, Delta: 1}}, []string{"scaleConfigs[some-api].minChange"}},
+		{"ReplicasOnly", ScaleConfig{Replicas: &five}, nil},
+		{"ReplicasAboveMax", ScaleConfig{Min: 1, Max: 1, Replicas: &fifty}, []string{"scaleConfigs[some-api].replicas"}},
+		{"RelativeMaxWithoutMin", ScaleConfig{MaxChange: &RelativeChange{Delta: 5}}, []string{"scaleConfigs[some-api].min"}},
+		{"RelativeMaxKeepingMin", ScaleConfig{MinChange: &RelativeChange{Factor: 1}, MaxChange: &RelativeChange{Delta: 5}}, nil},
+		{"TargetWithAlias", ScaleConfig{Target: "api", Deployment: "api", Min: 1, Max: 2}, nil},
+		{"TargetAliasMismatch", ScaleConfig{Target: "api", Deployment: "web", Min: 1, Max: 2}, []string{"scaleConfigs[some-api].deployment"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ScaleConfigs{"some-api": test.config}.validate(field.NewPath("scaleConfigs"))
+
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, test.fields, fields)
+		})
+	}
+
+	assert.Len(t, ScaleConfigs{}.validate(field.NewPath("scaleConfigs")), 1)
+}
+
+func TestValidateScaleConfigs_ResourceQuota(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&apiv1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "shop"},
+		Spec:       apiv1.ResourceQuotaSpec{Hard: apiv1.ResourceList{apiv1.ResourcePods: resource.MustParse("20")}},
+	})
+	facade := newTestFacade(withClients(clientset, nil))
+
+	err := facade.ValidateScaleConfigs(context.TODO(), ScaleConfigs{
+		"checkout": {Namespace: "shop", Min: 5, Max: 10},
+		"cart":     {Namespace: "shop", Min: 5, Max: 10},
+		"search":   {Namespace: "catalog", Min: 50, Max: 100},
+	})
+	assert.Nil(t, err)
+
+	err = facade.ValidateScaleConfigs(context.TODO(), ScaleConfigs{
+		"checkout": {Namespace: "shop", Min: 5, Max: 15},
+		"cart":     {Namespace: "shop", Min: 5, Max: 10},
+	})
+	assert.True(t, errors.IsInvalid(err))
+
+	causes := err.(errors.APIStatus).Status().Details.Causes
+	assert.Len(t, causes, 2)
+	assert.Contains(t, causes[0].Message, "ResourceQuota pods allows 20")
+}
+
+func TestSaveProfile_InvalidConfigs(t *testing.T) {
+	err := newTestFacade().SaveProfile(context.TODO(), Profile{Name: "broken", ScaleConfigs: ScaleConfigs{"some-api": {Min: 10, Max: 2}}})
+
+	assert.True(t, errors.IsInvalid(err))
+}
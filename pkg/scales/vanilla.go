@@ -1,35 +1,70 @@
 package scales
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 )
 
 // Implements Scaler Interface
 type vanillaHpa struct {
 	logger    *logrus.Logger
+	timeout   time.Duration
 	k8sHelper k8sHelperInterface
 }
 
-func newVanillaHpa(k8sHelper k8sHelperInterface, logger *logrus.Logger) *vanillaHpa {
+func newVanillaHpa(k8sHelper k8sHelperInterface, logger *logrus.Logger, timeout time.Duration) *vanillaHpa {
 	return &vanillaHpa{
 		logger:    logger,
+		timeout:   timeout,
 		k8sHelper: k8sHelper,
 	}
 }
 
-func (hpa *vanillaHpa) Scale(config ScaleConfig) error {
+// Patches only min and max, so concurrent writes to the rest of the HPA are kept
+func (hpa *vanillaHpa) Scale(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
 	helper := hpa.k8sHelper
-	hpaConfig, err := helper.getHpaWithTimeout(config.Name, 500*time.Millisecond)
+	hpaConfig, err := hpa.getHpa(ctx, config)
 
 	if err != nil {
-		return err
+		return config, err
 	}
 
-	minReplicas := int32(config.Min)
-	hpaConfig.Spec.MinReplicas = &minReplicas
-	hpaConfig.Spec.MaxReplicas = int32(config.Max)
+	min, max := hpaBounds(hpaConfig)
+	if config, err = config.resolve(min, max); err != nil {
+		return config, err
+	}
+
+	return config, helper.patchHpaWithTimeout(ctx, config.targetNamespace(), hpaConfig.Name, int32(config.Min), int32(config.Max), hpa.timeout)
+}
+
+// Gets the HPA by name, or through its scaleTargetRef when no name is set
+func (hpa *vanillaHpa) getHpa(ctx context.Context, config ScaleConfig) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if config.Hpa != "" {
+		return hpa.k8sHelper.getHpaWithTimeout(ctx, config.targetNamespace(), config.Hpa, hpa.timeout)
+	}
+	return hpa.k8sHelper.getHpaForTargetWithTimeout(ctx, config.targetNamespace(), config.targetKind(), config.targetName(), hpa.timeout)
+}
+
+// Returns config with min and max read from the HPA
+func (hpa *vanillaHpa) CurrentConfig(ctx context.Context, config ScaleConfig) (ScaleConfig, error) {
+	hpaConfig, err := hpa.getHpa(ctx, config)
 
-	return helper.updateHpaWithTimeout(config.Name, hpaConfig, 500)
+	if err != nil {
+		return config, err
+	}
+
+	config.Min, config.Max = hpaBounds(hpaConfig)
+	return config, nil
+}
+
+func hpaBounds(hpa *autoscalingv2.HorizontalPodAutoscaler) (int, int) {
+	// HPA defaults minReplicas to 1 when not set
+	min := 1
+	if hpa.Spec.MinReplicas != nil {
+		min = int(*hpa.Spec.MinReplicas)
+	}
+	return min, int(hpa.Spec.MaxReplicas)
 }
@@ -1,22 +1,29 @@
 package scales
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
-	v1 "k8s.io/api/autoscaling/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v2 "k8s.io/api/autoscaling/v2"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 var (
-	fakeHpaMocks = v1.HorizontalPodAutoscaler{
+	fakeHpaMocks = v2.HorizontalPodAutoscaler{
 		TypeMeta:   metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{},
-		Spec:       v1.HorizontalPodAutoscalerSpec{},
-		Status:     v1.HorizontalPodAutoscalerStatus{},
+		Spec:       v2.HorizontalPodAutoscalerSpec{},
+		Status:     v2.HorizontalPodAutoscalerStatus{},
 	}
 )
 
@@ -25,6 +32,7 @@ func TestVanillaScaleSuccess(t *testing.T) {
 	defer ctrl.Finish()
 	scaleConfig := ScaleConfig{
 		Name:        "NormalDeploy",
+		Hpa:         "NormalDeploy",
 		Min:         30,
 		Max:         50,
 		HpaOperator: false,
@@ -32,22 +40,22 @@ func TestVanillaScaleSuccess(t *testing.T) {
 	}
 
 	k8sHelperMock := NewMockk8sHelperInterface(ctrl)
+	hpa := fakeHpaMocks
+	hpa.Name = scaleConfig.Hpa
 	k8sHelperMock.
 		EXPECT().
-		getHpaWithTimeout(scaleConfig.Name, 500*time.Millisecond).
-		Return(&fakeHpaMocks, nil)
+		getHpaWithTimeout(gomock.Any(), scaleConfig.Name, scaleConfig.Hpa, 500*time.Millisecond).
+		Return(&hpa, nil)
 
 	k8sHelperMock.
 		EXPECT().
-		updateHpaWithTimeout(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil).AnyTimes()
+		patchHpaWithTimeout(gomock.Any(), scaleConfig.Name, scaleConfig.Hpa, int32(scaleConfig.Min), int32(scaleConfig.Max), gomock.Any()).
+		Return(nil)
 
-	scaler := newVanillaHpa(k8sHelperMock, &fakeLogger)
-	err := scaler.Scale(scaleConfig)
+	scaler := newVanillaHpa(k8sHelperMock, &fakeLogger, 500*time.Millisecond)
+	_, err := scaler.Scale(context.TODO(), scaleConfig)
 
 	assert.Nil(t, err)
-	assert.Equal(t, int32(scaleConfig.Min), *fakeHpaMocks.Spec.MinReplicas)
-	assert.Equal(t, int32(scaleConfig.Max), fakeHpaMocks.Spec.MaxReplicas)
 }
 
 func TestVanillaScaleError(t *testing.T) {
@@ -57,7 +65,7 @@ func TestVanillaScaleError(t *testing.T) {
 	k8sHelperMock := NewMockk8sHelperInterface(ctrl)
 	k8sHelperMock.
 		EXPECT().
-		getHpaWithTimeout(gomock.Any(), gomock.Any()).
+		getHpaForTargetWithTimeout(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		AnyTimes().Return(nil, fmt.Errorf("Fake error"))
 
 	scaleConfig := ScaleConfig{
@@ -65,8 +73,141 @@ func TestVanillaScaleError(t *testing.T) {
 		Max: 50,
 	}
 
-	scaler := newVanillaHpa(k8sHelperMock, &fakeLogger)
-	err := scaler.Scale(scaleConfig)
+	scaler := newVanillaHpa(k8sHelperMock, &fakeLogger, 500*time.Millisecond)
+	_, err := scaler.Scale(context.TODO(), scaleConfig)
 
 	assert.NotNil(t, err)
 }
+
+func TestVanillaScale_HpaV2KeepsMetricsAndBehavior(t *testing.T) {
+	utilization := int32(70)
+	stabilization := int32(300)
+	minReplicas := int32(2)
+	hpa := &v2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-hpa", Namespace: "shop"},
+		Spec: v2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: v2.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout", APIVersion: "apps/v1"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+			Metrics: []v2.MetricSpec{
+				{
+					Type: v2.ResourceMetricSourceType,
+					Resource: &v2.ResourceMetricSource{
+						Name:   apiv1.ResourceCPU,
+						Target: v2.MetricTarget{Type: v2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				},
+				{
+					Type: v2.ResourceMetricSourceType,
+					Resource: &v2.ResourceMetricSource{
+						Name:   apiv1.ResourceMemory,
+						Target: v2.MetricTarget{Type: v2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				},
+			},
+			Behavior: &v2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &v2.HPAScalingRules{StabilizationWindowSeconds: &stabilization},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(hpa)
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: v2.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"}},
+		},
+	}
+
+	helper := &k8sHelper{clientset: clientset}
+	scaler := newVanillaHpa(helper, &fakeLogger, 500*time.Millisecond)
+	_, err := scaler.Scale(context.TODO(), ScaleConfig{Name: "checkout", Namespace: "shop", Deployment: "checkout", Min: 30, Max: 50})
+	assert.Nil(t, err)
+
+	updated, err := clientset.AutoscalingV2().HorizontalPodAutoscalers("shop").Get(context.TODO(), "checkout-hpa", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(30), *updated.Spec.MinReplicas)
+	assert.Equal(t, int32(50), updated.Spec.MaxReplicas)
+	assert.Equal(t, hpa.Spec.Metrics, updated.Spec.Metrics)
+	assert.Equal(t, hpa.Spec.Behavior, updated.Spec.Behavior)
+}
+
+func TestVanillaScale_HpaV1Fallback(t *testing.T) {
+	utilization := int32(70)
+	minReplicas := int32(2)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-hpa", Namespace: "shop"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef:                 autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout", APIVersion: "apps/v1"},
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    10,
+			TargetCPUUtilizationPercentage: &utilization,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(hpa)
+	helper := &k8sHelper{clientset: clientset}
+	scaler := newVanillaHpa(helper, &fakeLogger, 500*time.Millisecond)
+
+	current, err := scaler.CurrentConfig(context.TODO(), ScaleConfig{Name: "checkout", Namespace: "shop", Deployment: "checkout"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, current.Min)
+	assert.Equal(t, 10, current.Max)
+
+	_, err = scaler.Scale(context.TODO(), ScaleConfig{Name: "checkout", Namespace: "shop", Hpa: "checkout-hpa", Min: 30, Max: 50})
+	assert.Nil(t, err)
+
+	updated, err := clientset.AutoscalingV1().HorizontalPodAutoscalers("shop").Get(context.TODO(), "checkout-hpa", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(30), *updated.Spec.MinReplicas)
+	assert.Equal(t, int32(50), updated.Spec.MaxReplicas)
+	assert.Equal(t, utilization, *updated.Spec.TargetCPUUtilizationPercentage)
+}
+
+func TestVanillaScale_RetriesOnConflict(t *testing.T) {
+	minReplicas := int32(2)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-hpa", Namespace: "shop"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "checkout", APIVersion: "apps/v1"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(hpa)
+	conflicts := 2
+	clientset.PrependReactor("patch", "horizontalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts == 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		return true, nil, errors.NewConflict(autoscalingv1.Resource("horizontalpodautoscalers"), "checkout-hpa", fmt.Errorf("object was modified"))
+	})
+
+	helper := &k8sHelper{clientset: clientset}
+	_, err := newVanillaHpa(helper, &fakeLogger, 500*time.Millisecond).Scale(context.TODO(), ScaleConfig{Name: "checkout", Namespace: "shop", Hpa: "checkout-hpa", Min: 30, Max: 50})
+	assert.Nil(t, err)
+
+	updated, err := clientset.AutoscalingV1().HorizontalPodAutoscalers("shop").Get(context.TODO(), "checkout-hpa", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(30), *updated.Spec.MinReplicas)
+	assert.Equal(t, int32(50), updated.Spec.MaxReplicas)
+}
+
+func TestVanillaScale_SurfacesPersistentConflict(t *testing.T) {
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "checkout-hpa", Namespace: "shop"},
+	}
+
+	clientset := fake.NewSimpleClientset(hpa)
+	clientset.PrependReactor("patch", "horizontalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(autoscalingv1.Resource("horizontalpodautoscalers"), "checkout-hpa", fmt.Errorf("object was modified"))
+	})
+
+	helper := &k8sHelper{clientset: clientset}
+	_, err := newVanillaHpa(helper, &fakeLogger, 500*time.Millisecond).Scale(context.TODO(), ScaleConfig{Name: "checkout", Namespace: "shop", Hpa: "checkout-hpa", Min: 30, Max: 50})
+
+	assert.True(t, errors.IsConflict(err))
+	assert.Contains(t, err.Error(), "giving up after")
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
+)
+
+func getProfiles(c *gin.Context) {
+	profiles, err := facade.ListProfiles(c.Request.Context())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(200, profiles)
+}
+
+func getProfile(c *gin.Context) {
+	profile, err := facade.GetProfile(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, profile)
+}
+
+// Creates or replaces the profile with the scale configs in the body
+func putProfile(c *gin.Context) {
+	var configs scales.ScaleConfigs
+
+	if err := c.ShouldBindJSON(&configs); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	profile := scales.Profile{Name: c.Param("name"), ScaleConfigs: configs}
+	if err := facade.SaveProfile(c.Request.Context(), profile); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, profile)
+}
+
+// Scales the profile targets, honoring the same headers as POST /scaleConfigs
+func postApplyProfile(c *gin.Context) {
+	options, err := updateOptions(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	wait, err := waitRequested(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	mode, dryRun, err := dryRunMode(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if dryRun {
+		profile, err := facade.GetProfile(ctx, c.Param("name"))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, facade.Plan(ctx, profile.ScaleConfigs, mode))
+		return
+	}
+
+	job, err := facade.ApplyProfile(ctx, c.Param("name"), options)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if wait {
+		// The job keeps running when the client goes away
+		job, _ = facade.WaitJob(c.Request.Context(), job.ID)
+		c.JSON(jobResultStatus(job), job)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Your request is being processed", "jobId": job.ID, "capacity": job.Capacity})
+}
+
+// Restores the original configs of the profile targets
+func postRevertProfile(c *gin.Context) {
+	ctx, err := requestContext(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	restored, err := facade.RevertProfile(ctx, c.Param("name"))
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{"message": err.Error(), "restored": restored})
+		return
+	}
+
+	c.JSON(200, restored)
+}
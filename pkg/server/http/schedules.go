@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
+)
+
+func getSchedules(c *gin.Context) {
+	schedules, err := facade.ListSchedules()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(200, schedules)
+}
+
+// Creates a schedule from the body, either a cron expression with a duration or a start and end
+func postSchedule(c *gin.Context) {
+	var schedule scales.Schedule
+
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	schedule, err := facade.CreateSchedule(schedule)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func deleteSchedule(c *gin.Context) {
+	if err := facade.DeleteSchedule(c.Param("id")); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
+)
+
+// Returns the targets the selection in the body resolves to, without scaling them
+func postSelectionPreview(c *gin.Context) {
+	var selection scales.Selection
+
+	if err := c.ShouldBindJSON(&selection); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	configs, err := facade.SelectTargets(ctx, selection)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, configs)
+}
+
+// Scales the targets the selection in the body resolves to, honoring the same headers as POST /scaleConfigs
+func postSelection(c *gin.Context) {
+	var selection scales.Selection
+
+	options, err := updateOptions(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	wait, err := waitRequested(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&selection); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	mode, dryRun, err := dryRunMode(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	configs, err := facade.SelectTargets(ctx, selection)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if len(configs) == 0 {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "selector matched no targets"})
+		return
+	}
+
+	if err := facade.ValidateScaleConfigs(ctx, configs); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if dryRun {
+		c.JSON(200, facade.Plan(ctx, configs, mode))
+		return
+	}
+
+	job, err := facade.SubmitJob(ctx, configs, options)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if wait {
+		// The job keeps running when the client goes away
+		job, _ = facade.WaitJob(c.Request.Context(), job.ID)
+		c.JSON(jobResultStatus(job), job)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Your request is being processed", "jobId": job.ID, "targets": configs, "capacity": job.Capacity})
+}
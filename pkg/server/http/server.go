@@ -1,36 +1,72 @@
 package http
 
 import (
+	"context"
+	goerrors "errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pliniogsnascimento/pod-scaler-for-tests/pkg/scales"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
 )
 
-var logger *logrus.Logger
+var (
+	logger *logrus.Logger
+	facade *scales.ScalesFacade
+)
 
 // Start http server with routes
-func StartServer(port string, defaultLogger *logrus.Logger) {
+func StartServer(port string, scalesFacade *scales.ScalesFacade, defaultLogger *logrus.Logger) error {
 	logger = defaultLogger
+	facade = scalesFacade
+
+	if err := facade.ResumeRestores(); err != nil {
+		logger.Errorf("Unable to resume pending restores: %s\n", err)
+	}
+
+	if err := facade.ResumeSchedules(); err != nil {
+		logger.Errorf("Unable to resume schedules: %s\n", err)
+	}
 
 	r := gin.Default()
 	r.POST("/scaleConfigs", postScaleConfigs)
 	r.GET("/scaleConfigs", getScaleConfigs)
-	r.Run(fmt.Sprintf("0.0.0.0:%s", port))
+	r.GET("/targets/:namespace/:name", getTarget)
+	r.POST("/scaleConfigs/select", postSelection)
+	r.POST("/scaleConfigs/select/preview", postSelectionPreview)
+	r.GET("/snapshots", getSnapshots)
+	r.POST("/snapshots/restore", postRestore)
+	r.GET("/jobs", getJobs)
+	r.GET("/jobs/:id", getJob)
+	r.DELETE("/jobs/:id", deleteJob)
+	r.GET("/profiles", getProfiles)
+	r.GET("/profiles/:name", getProfile)
+	r.PUT("/profiles/:name", putProfile)
+	r.POST("/profiles/:name/apply", postApplyProfile)
+	r.POST("/profiles/:name/revert", postRevertProfile)
+	r.GET("/schedules", getSchedules)
+	r.POST("/schedules", postSchedule)
+	r.DELETE("/schedules/:id", deleteSchedule)
+	return r.Run(fmt.Sprintf("0.0.0.0:%s", port))
 }
 
 func postScaleConfigs(c *gin.Context) {
 	var configs scales.ScaleConfigs
-	var sleepDuration time.Duration
-	var err error
-	facade := scales.NewScalesFacade(logger)
 
-	sleepString := c.Request.Header.Get("sleep")
-	if sleepDuration, err = time.ParseDuration(sleepString); err != nil {
-		sleepDuration = time.Duration(0)
+	options, err := updateOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	wait, err := waitRequested(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
 	}
 
 	if err := c.ShouldBindJSON(&configs); err != nil {
@@ -38,45 +74,309 @@ func postScaleConfigs(c *gin.Context) {
 		return
 	}
 
-	// clientset, err := facade.GetClientset()
+	ctx, err := requestContext(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
-	go facade.UpdateWithConcurrency(configs, &sleepDuration)
+	mode, dryRun, err := dryRunMode(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := facade.ValidateScaleConfigs(ctx, configs); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if dryRun {
+		c.JSON(200, facade.Plan(ctx, configs, mode))
 		return
 	}
-	c.JSON(200, map[string]string{"message": "Your request is being processed"})
+
+	job, err := facade.SubmitJob(ctx, configs, options)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if wait {
+		// The job keeps running when the client goes away
+		job, _ = facade.WaitJob(c.Request.Context(), job.ID)
+		c.JSON(jobResultStatus(job), job)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Your request is being processed", "jobId": job.ID, "capacity": job.Capacity})
+}
+
+// Whether the request blocks until its job finishes, set through the wait query parameter or header
+func waitRequested(c *gin.Context) (bool, error) {
+	wait := c.Query("wait")
+	if wait == "" {
+		wait = c.Request.Header.Get("wait")
+	}
+
+	if wait == "" {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(wait)
+	if err != nil {
+		return false, fmt.Errorf("invalid wait %q, expected a boolean", wait)
+	}
+	return enabled, nil
+}
+
+// 200 when every target scaled, 207 when only some did, 422 when none did
+func jobResultStatus(job scales.Job) int {
+	switch {
+	case job.Status == scales.JobSucceeded:
+		return http.StatusOK
+	case len(job.Changed()) > 0:
+		return http.StatusMultiStatus
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// Time waited for targets to become ready when the waitForReady header is true
+const defaultReadyTimeout = 5 * time.Minute
+
+// Options set through the sleep, ttl, capacityPolicy, waitForReady,
+// concurrency, qps, burst, wavePercent and waveInterval headers
+func updateOptions(c *gin.Context) (scales.UpdateOptions, error) {
+	var options scales.UpdateOptions
+	var err error
+
+	sleepString := c.Request.Header.Get("sleep")
+	if options.Sleep, err = time.ParseDuration(sleepString); err != nil {
+		options.Sleep = time.Duration(0)
+	}
+
+	if ttlString := c.Request.Header.Get("ttl"); ttlString != "" {
+		if options.TTL, err = time.ParseDuration(ttlString); err != nil || options.TTL <= 0 {
+			return options, fmt.Errorf("invalid ttl %q", ttlString)
+		}
+	}
+
+	// The pre-flight check is opt-in, it reads every node and pod of the cluster
+	switch policy := scales.CapacityPolicy(c.Request.Header.Get("capacityPolicy")); policy {
+	case "":
+		options.CapacityPolicy = scales.CapacityIgnore
+	case scales.CapacityIgnore, scales.CapacityWarn, scales.CapacityRefuse:
+		options.CapacityPolicy = policy
+	default:
+		return options, fmt.Errorf("invalid capacityPolicy %q, expected ignore, warn or refuse", policy)
+	}
+
+	for _, header := range []struct {
+		name  string
+		value *int
+	}{{"concurrency", &options.Concurrency}, {"burst", &options.Burst}, {"wavePercent", &options.WavePercent}} {
+		if value := c.Request.Header.Get(header.name); value != "" {
+			if *header.value, err = strconv.Atoi(value); err != nil {
+				return options, fmt.Errorf("invalid %s %q", header.name, value)
+			}
+		}
+	}
+
+	if qps := c.Request.Header.Get("qps"); qps != "" {
+		value, err := strconv.ParseFloat(qps, 32)
+		if err != nil {
+			return options, fmt.Errorf("invalid qps %q", qps)
+		}
+		options.QPS = float32(value)
+	}
+
+	if interval := c.Request.Header.Get("waveInterval"); interval != "" {
+		if options.WaveInterval, err = time.ParseDuration(interval); err != nil {
+			return options, fmt.Errorf("invalid waveInterval %q", interval)
+		}
+	}
+
+	// Either a boolean, waiting the default time, or the time to wait
+	if wait := c.Request.Header.Get("waitForReady"); wait != "" {
+		if enabled, err := strconv.ParseBool(wait); err == nil {
+			if enabled {
+				options.WaitForReady = defaultReadyTimeout
+			}
+		} else if options.WaitForReady, err = time.ParseDuration(wait); err != nil || options.WaitForReady <= 0 {
+			return options, fmt.Errorf("invalid waitForReady %q, expected a boolean or a positive duration", wait)
+		}
+	}
+
+	return options, nil
+}
+
+// Dry run mode set through the dryRun header, false when the request should scale
+func dryRunMode(c *gin.Context) (scales.DryRunMode, bool, error) {
+	switch dryRun := c.Request.Header.Get("dryRun"); dryRun {
+	case "", "false":
+		return "", false, nil
+	case "true", string(scales.DryRunClient):
+		return scales.DryRunClient, true, nil
+	case string(scales.DryRunServer):
+		return scales.DryRunServer, true, nil
+	default:
+		return "", false, fmt.Errorf("invalid dryRun %q, expected true, client or server", dryRun)
+	}
 }
 
+// Describes the targets given as target=namespace/name query parameters, kind applying to all of them
 func getScaleConfigs(c *gin.Context) {
-	var configs scales.ScaleConfigs
-	facade := scales.NewScalesFacade(logger)
+	targets := c.QueryArray("target")
+	if len(targets) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "at least one target=namespace/name query parameter is required"})
+		return
+	}
 
-	if err := c.ShouldBindJSON(&configs); err != nil {
+	ctx, err := requestContext(c)
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
-	clientset, err := facade.GetClientset()
+	infos, err := facade.DescribeTargets(ctx, c.Query("kind"), targets...)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if len(infos) == 0 {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "no target found"})
+		return
+	}
 
+	c.JSON(200, infos)
+}
+
+func getTarget(c *gin.Context) {
+	ctx, err := requestContext(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
 
-	currentConfig, err := facade.GetHpaInfo(clientset, configs, logger)
+	info, err := facade.DescribeTarget(ctx, c.Param("namespace"), c.Query("kind"), c.Param("name"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, info)
+}
+
+func getSnapshots(c *gin.Context) {
+	snapshots, err := facade.GetSnapshots()
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 
-	if len(currentConfig) <= 0 {
-		c.AbortWithStatus(http.StatusNotFound)
+	c.JSON(200, snapshots)
+}
+
+// Restores the targets listed in the body by their namespace/kind/name snapshot keys,
+// or every snapshot when the body is empty
+func postRestore(c *gin.Context) {
+	var keys []string
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&keys); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
 	}
 
-	c.JSON(200, currentConfig)
+	restored, err := facade.Restore(ctx, keys...)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error(), "restored": restored})
+		return
+	}
+
+	c.JSON(200, restored)
+}
+
+func getJobs(c *gin.Context) {
+	c.JSON(200, facade.ListJobs())
+}
+
+func getJob(c *gin.Context) {
+	job, ok := facade.GetJob(c.Param("id"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("job %s not found", c.Param("id"))})
+		return
+	}
+
+	c.JSON(200, job)
+}
+
+// Cancels a job, reporting the targets it had already changed
+func deleteJob(c *gin.Context) {
+	job, ok := facade.CancelJob(c.Request.Context(), c.Param("id"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("job %s not found", c.Param("id"))})
+		return
+	}
+
+	c.JSON(200, gin.H{"job": job, "changed": job.Changed()})
+}
+
+// Context of the request, carrying the policy overridden through the timeout and retries headers
+func requestContext(c *gin.Context) (context.Context, error) {
+	var policy scales.Policy
+	var err error
+
+	if timeoutString := c.Request.Header.Get("timeout"); timeoutString != "" {
+		if policy.Timeout, err = time.ParseDuration(timeoutString); err != nil || policy.Timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", timeoutString)
+		}
+	}
+
+	if retriesString := c.Request.Header.Get("retries"); retriesString != "" {
+		if policy.Retries, err = strconv.Atoi(retriesString); err != nil {
+			return nil, fmt.Errorf("invalid retries %q", retriesString)
+		}
+	}
+
+	return scales.WithPolicy(c.Request.Context(), policy), nil
+}
+
+// Aborts with the status matching a facade error, listing the fields at fault of invalid input
+func abortWithError(c *gin.Context, err error) {
+	body := gin.H{"message": err.Error()}
+
+	var capacityErr *scales.CapacityError
+	if goerrors.As(err, &capacityErr) {
+		body["capacity"] = capacityErr.Report
+		c.AbortWithStatusJSON(http.StatusConflict, body)
+		return
+	}
+
+	if status, ok := err.(errors.APIStatus); ok && errors.IsInvalid(err) && status.Status().Details != nil {
+		body["errors"] = status.Status().Details.Causes
+	}
+	c.AbortWithStatusJSON(errorStatus(err), body)
+}
+
+// Status code matching the kind of a facade error
+func errorStatus(err error) int {
+	switch {
+	case errors.IsNotFound(err):
+		return http.StatusNotFound
+	case errors.IsBadRequest(err):
+		return http.StatusBadRequest
+	case errors.IsInvalid(err):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
 }